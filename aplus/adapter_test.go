@@ -0,0 +1,208 @@
+/*
+	Promises/A+ conformance suite adapted to Go
+
+	Cases follow https://github.com/promises-aplus/promises-tests, every
+	chain is executed by EventLoop, so "later" of JS is the next drain of loop
+	and results don't depend on scheduling of goroutines.
+
+	Differences of Go:
+		- handlers are func values, "not a function" means nil handler;
+		- rejection reason is error, fulfillment value can't be error;
+		- throw is panic, promise is rejected by PanicError with panic value;
+		- thenable is value with method Then(resolve, reject), accessor of then
+		  doesn't exist, so its cases are skipped;
+		- 2.2.5 (handlers are called without this) isn't applicable.
+ */
+package aplus
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	promise "github.com/danevge/go-promise"
+)
+
+/*
+	Promise of test with own loop
+ */
+type adapter struct {
+	t    *testing.T
+	loop *promise.EventLoop
+	ctx  context.Context
+}
+
+func newAdapter(t *testing.T) *adapter {
+
+	loop := promise.NewEventLoop()
+	return &adapter{t: t, loop: loop, ctx: promise.WithExecutor(context.Background(), loop)}
+}
+
+func (a *adapter) deferred() *promise.Deferred {
+
+	return promise.NewDeferredWithContext(a.ctx)
+}
+
+func (a *adapter) resolved(value interface{}) *promise.Promise {
+
+	d := a.deferred()
+	d.Resolve(value)
+	return d.Promise
+}
+
+func (a *adapter) rejected(reason error) *promise.Promise {
+
+	d := a.deferred()
+	d.Reject(reason)
+	return d.Promise
+}
+
+/*
+	Run all jobs, it's "after some time" of JS
+ */
+func (a *adapter) drain() {
+
+	a.loop.RunUntilIdle()
+}
+
+/*
+	Call f by job of loop, it's setTimeout of JS
+ */
+func (a *adapter) later(f func()) {
+
+	a.loop.Execute(f)
+}
+
+type dummyValue struct {
+	dummy string
+}
+
+var (
+	// fulfillment value which isn't checked
+	dummy = &dummyValue{dummy: "dummy"}
+	// value for strict checks
+	sentinel = &dummyValue{dummy: "sentinel"}
+	other    = &dummyValue{dummy: "other"}
+
+	errDummy    = errors.New("dummy")
+	errSentinel = errors.New("sentinel")
+	errOther    = errors.New("other")
+)
+
+/*
+	Test is called for promise and returns check which is called after all jobs
+ */
+type promiseTest func(t *testing.T, a *adapter, p *promise.Promise) func()
+
+/*
+	Run test for already-fulfilled, immediately-fulfilled and eventually-fulfilled promise
+ */
+func testFulfilled(t *testing.T, value interface{}, test promiseTest) {
+
+	t.Run("already-fulfilled", func(t *testing.T) {
+		a := newAdapter(t)
+		check := test(t, a, a.resolved(value))
+		a.drain()
+		check()
+	})
+	t.Run("immediately-fulfilled", func(t *testing.T) {
+		a := newAdapter(t)
+		d := a.deferred()
+		check := test(t, a, d.Promise)
+		d.Resolve(value)
+		a.drain()
+		check()
+	})
+	t.Run("eventually-fulfilled", func(t *testing.T) {
+		a := newAdapter(t)
+		d := a.deferred()
+		check := test(t, a, d.Promise)
+		a.drain()
+		d.Resolve(value)
+		a.drain()
+		check()
+	})
+}
+
+/*
+	Run test for already-rejected, immediately-rejected and eventually-rejected promise
+ */
+func testRejected(t *testing.T, reason error, test promiseTest) {
+
+	t.Run("already-rejected", func(t *testing.T) {
+		a := newAdapter(t)
+		check := test(t, a, a.rejected(reason))
+		a.drain()
+		check()
+	})
+	t.Run("immediately-rejected", func(t *testing.T) {
+		a := newAdapter(t)
+		d := a.deferred()
+		check := test(t, a, d.Promise)
+		d.Reject(reason)
+		a.drain()
+		check()
+	})
+	t.Run("eventually-rejected", func(t *testing.T) {
+		a := newAdapter(t)
+		d := a.deferred()
+		check := test(t, a, d.Promise)
+		a.drain()
+		d.Reject(reason)
+		a.drain()
+		check()
+	})
+}
+
+/*
+	Run test for promise resolved by x returned from handler of fulfilled and rejected promise
+ */
+func testPromiseResolution(t *testing.T, xFactory func(a *adapter) interface{}, test promiseTest) {
+
+	t.Run("via return from a fulfilled promise", func(t *testing.T) {
+		a := newAdapter(t)
+		p := a.resolved(dummy).Then(func(value interface{}) interface{} { return xFactory(a) })
+		check := test(t, a, p)
+		a.drain()
+		check()
+	})
+	t.Run("via return from a rejected promise", func(t *testing.T) {
+		a := newAdapter(t)
+		p := a.rejected(errDummy).Catch(func(err error) interface{} { return xFactory(a) })
+		check := test(t, a, p)
+		a.drain()
+		check()
+	})
+}
+
+/*
+	Counter of handler calls
+ */
+type calls struct {
+	count int
+	value interface{}
+	err   error
+}
+
+func (c *calls) onFulfilled(value interface{}) interface{} {
+
+	c.count++
+	c.value = value
+	return nil
+}
+
+func (c *calls) onRejected(err error) interface{} {
+
+	c.count++
+	c.err = err
+	return nil
+}
+
+/*
+	Thenable of tests
+ */
+type thenable func(resolve func(value interface{}), reject func(err error))
+
+func (t thenable) Then(resolve func(value interface{}), reject func(err error)) {
+	t(resolve, reject)
+}
@@ -0,0 +1,447 @@
+package aplus
+
+import (
+	"errors"
+	"testing"
+
+	promise "github.com/danevge/go-promise"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	Check of promise fulfilled by value
+ */
+func fulfilledWith(value interface{}) promiseTest {
+
+	return func(t *testing.T, a *adapter, p *promise.Promise) func() {
+		return func() {
+			assert.Equal(t, promise.Success, p.State())
+			assertSameValue(t, value, p.Value())
+		}
+	}
+}
+
+/*
+	Check of promise rejected by reason, panic(reason) of thenable is PanicError with reason
+ */
+func rejectedWith(reason error) promiseTest {
+
+	return func(t *testing.T, a *adapter, p *promise.Promise) func() {
+		return func() {
+			assert.Equal(t, promise.Rejected, p.State())
+			assert.True(t, p.Err() == reason || errors.Is(p.Err(), reason), "%v isn't %v", p.Err(), reason)
+		}
+	}
+}
+
+func pending(t *testing.T, a *adapter, p *promise.Promise) func() {
+
+	return func() { assert.Equal(t, promise.Pending, p.State()) }
+}
+
+/*
+	2.3.1: If promise and x refer to the same object, reject promise with an error as the reason
+ */
+func TestSpec2_3_1(t *testing.T) {
+
+	t.Run("via return from a fulfilled promise", func(t *testing.T) {
+		a := newAdapter(t)
+		var p *promise.Promise
+		p = a.resolved(dummy).Then(func(value interface{}) interface{} { return p })
+		a.drain()
+		assert.ErrorIs(t, p.Err(), promise.ErrChainingCycle)
+	})
+	t.Run("via return from a rejected promise", func(t *testing.T) {
+		a := newAdapter(t)
+		var p *promise.Promise
+		p = a.rejected(errDummy).Catch(func(err error) interface{} { return p })
+		a.drain()
+		assert.ErrorIs(t, p.Err(), promise.ErrChainingCycle)
+	})
+}
+
+/*
+	2.3.2: If x is a promise, adopt its state
+ */
+func TestSpec2_3_2(t *testing.T) {
+
+	t.Run("2.3.2.1: if x is pending, promise must remain pending until x is fulfilled or rejected", func(t *testing.T) {
+		testPromiseResolution(t, func(a *adapter) interface{} { return a.deferred().Promise }, pending)
+	})
+
+	t.Run("2.3.2.2: if/when x is fulfilled, fulfill promise with the same value", func(t *testing.T) {
+		t.Run("x is already-fulfilled", func(t *testing.T) {
+			testPromiseResolution(t, func(a *adapter) interface{} { return a.resolved(sentinel) },
+				fulfilledWith(sentinel))
+		})
+		t.Run("x is eventually-fulfilled", func(t *testing.T) {
+			testPromiseResolution(t, func(a *adapter) interface{} {
+				d := a.deferred()
+				a.later(func() { d.Resolve(sentinel) })
+				return d.Promise
+			}, fulfilledWith(sentinel))
+		})
+	})
+
+	t.Run("2.3.2.3: if/when x is rejected, reject promise with the same reason", func(t *testing.T) {
+		t.Run("x is already-rejected", func(t *testing.T) {
+			testPromiseResolution(t, func(a *adapter) interface{} { return a.rejected(errSentinel) },
+				rejectedWith(errSentinel))
+		})
+		t.Run("x is eventually-rejected", func(t *testing.T) {
+			testPromiseResolution(t, func(a *adapter) interface{} {
+				d := a.deferred()
+				a.later(func() { d.Reject(errSentinel) })
+				return d.Promise
+			}, rejectedWith(errSentinel))
+		})
+	})
+}
+
+/*
+	2.3.3: Otherwise, if x is thenable
+ */
+func TestSpec2_3_3(t *testing.T) {
+
+	t.Run("2.3.3.1: then is called once", func(t *testing.T) {
+		count := 0
+		testPromiseResolution(t, func(a *adapter) interface{} {
+			return thenable(func(resolve func(value interface{}), reject func(err error)) {
+				count++
+				resolve(sentinel)
+			})
+		}, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			count = 0
+			return func() {
+				assert.Equal(t, 1, count)
+				fulfilledWith(sentinel)(t, a, p)()
+			}
+		})
+	})
+
+	t.Run("2.3.3.3: then is called with resolvePromise and rejectPromise", func(t *testing.T) {
+		testPromiseResolution(t, func(a *adapter) interface{} {
+			return thenable(func(resolve func(value interface{}), reject func(err error)) {
+				assert.NotNil(t, resolve)
+				assert.NotNil(t, reject)
+				resolve(sentinel)
+			})
+		}, fulfilledWith(sentinel))
+	})
+
+	t.Run("2.3.3.3.1: if/when resolvePromise is called with value y, run [[Resolve]](promise, y)", func(t *testing.T) {
+		testResolvePromiseCalled(t)
+	})
+
+	t.Run("2.3.3.3.2: if/when rejectPromise is called with reason r, reject promise with r", func(t *testing.T) {
+		for name, reason := range reasons {
+			reason := reason
+			t.Run(name, func(t *testing.T) {
+				r := reason()
+				t.Run("r is rejected synchronously", func(t *testing.T) {
+					testPromiseResolution(t, func(a *adapter) interface{} {
+						return thenable(func(resolve func(value interface{}), reject func(err error)) { reject(r) })
+					}, rejectedWith(r))
+				})
+				t.Run("r is rejected asynchronously", func(t *testing.T) {
+					testPromiseResolution(t, func(a *adapter) interface{} {
+						return thenable(func(resolve func(value interface{}), reject func(err error)) {
+							a.later(func() { reject(r) })
+						})
+					}, rejectedWith(r))
+				})
+			})
+		}
+	})
+
+	t.Run("2.3.3.3.3: if both resolvePromise and rejectPromise are called, or multiple calls are made, the first call wins", func(t *testing.T) {
+		testFirstCallWins(t)
+	})
+
+	t.Run("2.3.3.3.4: if calling then panics", func(t *testing.T) {
+		testThenPanics(t)
+	})
+
+	t.Run("2.3.3.4: if x has Then field which isn't method, fulfill promise with x", func(t *testing.T) {
+		x := struct {
+			Then func(resolve func(value interface{}), reject func(err error))
+		}{}
+		testPromiseResolution(t, func(a *adapter) interface{} { return x }, fulfilledWith(x))
+	})
+}
+
+func testResolvePromiseCalled(t *testing.T) {
+
+	t.Run("y is not a thenable", func(t *testing.T) {
+		for name, value := range values {
+			value := value
+			t.Run(name, func(t *testing.T) {
+				testPromiseResolution(t, func(a *adapter) interface{} {
+					return thenable(func(resolve func(value interface{}), reject func(err error)) { resolve(value()) })
+				}, fulfilledWith(value()))
+			})
+		}
+	})
+
+	t.Run("y is a thenable", func(t *testing.T) {
+		for name, y := range fulfilledThenables {
+			y := y
+			t.Run(name, func(t *testing.T) {
+				testPromiseResolution(t, func(a *adapter) interface{} {
+					return thenable(func(resolve func(value interface{}), reject func(err error)) {
+						resolve(y(a, sentinel))
+					})
+				}, fulfilledWith(sentinel))
+			})
+		}
+		for name, y := range rejectedThenables {
+			y := y
+			t.Run(name, func(t *testing.T) {
+				testPromiseResolution(t, func(a *adapter) interface{} {
+					return thenable(func(resolve func(value interface{}), reject func(err error)) {
+						resolve(y(a, errSentinel))
+					})
+				}, rejectedWith(errSentinel))
+			})
+		}
+	})
+
+	t.Run("y is a thenable for a thenable", func(t *testing.T) {
+		for outerName, outer := range fulfilledThenables {
+			outer := outer
+			for innerName, inner := range fulfilledThenables {
+				inner := inner
+				t.Run(outerName+" for "+innerName, func(t *testing.T) {
+					testPromiseResolution(t, func(a *adapter) interface{} {
+						return thenable(func(resolve func(value interface{}), reject func(err error)) {
+							resolve(outer(a, inner(a, sentinel)))
+						})
+					}, fulfilledWith(sentinel))
+				})
+			}
+			for innerName, inner := range rejectedThenables {
+				inner := inner
+				t.Run(outerName+" for "+innerName, func(t *testing.T) {
+					testPromiseResolution(t, func(a *adapter) interface{} {
+						return thenable(func(resolve func(value interface{}), reject func(err error)) {
+							resolve(outer(a, inner(a, errSentinel)))
+						})
+					}, rejectedWith(errSentinel))
+				})
+			}
+		}
+	})
+}
+
+func testFirstCallWins(t *testing.T) {
+
+	type caseOf struct {
+		x     func(a *adapter) interface{}
+		check promiseTest
+	}
+	then := func(f func(a *adapter, resolve func(value interface{}), reject func(err error))) func(a *adapter) interface{} {
+		return func(a *adapter) interface{} {
+			return thenable(func(resolve func(value interface{}), reject func(err error)) { f(a, resolve, reject) })
+		}
+	}
+	eventually := func(a *adapter, settle func(d *promise.Deferred)) *promise.Promise {
+		d := a.deferred()
+		a.later(func() { settle(d) })
+		return d.Promise
+	}
+	var saved struct {
+		resolve func(value interface{})
+		reject  func(err error)
+	}
+
+	cases := map[string]caseOf{
+		"calling resolvePromise then rejectPromise, both synchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				resolve(sentinel)
+				reject(errOther)
+			}), fulfilledWith(sentinel)},
+		"calling resolvePromise synchronously then rejectPromise asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				resolve(sentinel)
+				a.later(func() { reject(errOther) })
+			}), fulfilledWith(sentinel)},
+		"calling resolvePromise then rejectPromise, both asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				a.later(func() { resolve(sentinel) })
+				a.later(func() { reject(errOther) })
+			}), fulfilledWith(sentinel)},
+		"calling resolvePromise with an asynchronously-fulfilled promise, then calling rejectPromise, both synchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				resolve(eventually(a, func(d *promise.Deferred) { d.Resolve(sentinel) }))
+				reject(errOther)
+			}), fulfilledWith(sentinel)},
+		"calling resolvePromise with an asynchronously-rejected promise, then calling rejectPromise, both synchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				resolve(eventually(a, func(d *promise.Deferred) { d.Reject(errSentinel) }))
+				reject(errOther)
+			}), rejectedWith(errSentinel)},
+		"calling rejectPromise then resolvePromise, both synchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				reject(errSentinel)
+				resolve(other)
+			}), rejectedWith(errSentinel)},
+		"calling rejectPromise synchronously then resolvePromise asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				reject(errSentinel)
+				a.later(func() { resolve(other) })
+			}), rejectedWith(errSentinel)},
+		"calling rejectPromise then resolvePromise, both asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				a.later(func() { reject(errSentinel) })
+				a.later(func() { resolve(other) })
+			}), rejectedWith(errSentinel)},
+		"calling resolvePromise twice synchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				resolve(sentinel)
+				resolve(other)
+			}), fulfilledWith(sentinel)},
+		"calling resolvePromise twice, first synchronously then asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				resolve(sentinel)
+				a.later(func() { resolve(other) })
+			}), fulfilledWith(sentinel)},
+		"calling resolvePromise twice, both times asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				a.later(func() { resolve(sentinel) })
+				a.later(func() { resolve(other) })
+			}), fulfilledWith(sentinel)},
+		"calling resolvePromise with an asynchronously-fulfilled promise, then calling it again, both times synchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				resolve(eventually(a, func(d *promise.Deferred) { d.Resolve(sentinel) }))
+				resolve(other)
+			}), fulfilledWith(sentinel)},
+		"calling resolvePromise with an asynchronously-fulfilled promise, then calling it again, both times asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				p := eventually(a, func(d *promise.Deferred) { d.Resolve(sentinel) })
+				a.later(func() { resolve(p) })
+				a.later(func() { resolve(other) })
+			}), fulfilledWith(sentinel)},
+		"calling rejectPromise twice synchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				reject(errSentinel)
+				reject(errOther)
+			}), rejectedWith(errSentinel)},
+		"calling rejectPromise twice, first synchronously then asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				reject(errSentinel)
+				a.later(func() { reject(errOther) })
+			}), rejectedWith(errSentinel)},
+		"calling rejectPromise twice, both times asynchronously": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				a.later(func() { reject(errSentinel) })
+				a.later(func() { reject(errOther) })
+			}), rejectedWith(errSentinel)},
+		"saving and abusing resolvePromise and rejectPromise": {
+			then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				saved.resolve, saved.reject = resolve, reject
+				resolve(sentinel)
+				a.later(func() {
+					saved.resolve(other)
+					saved.reject(errOther)
+				})
+			}), fulfilledWith(sentinel)},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run(name, func(t *testing.T) {
+			testPromiseResolution(t, c.x, c.check)
+		})
+	}
+}
+
+func testThenPanics(t *testing.T) {
+
+	then := func(f func(a *adapter, resolve func(value interface{}), reject func(err error))) func(a *adapter) interface{} {
+		return func(a *adapter) interface{} {
+			return thenable(func(resolve func(value interface{}), reject func(err error)) {
+				f(a, resolve, reject)
+				panic(errOther)
+			})
+		}
+	}
+
+	t.Run("2.3.3.3.4.1: if resolvePromise or rejectPromise have been called, ignore it", func(t *testing.T) {
+		cases := map[string]struct {
+			x     func(a *adapter) interface{}
+			check promiseTest
+		}{
+			"resolvePromise was called with a non-thenable": {
+				then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+					resolve(sentinel)
+				}), fulfilledWith(sentinel)},
+			"resolvePromise was called with an asynchronously-fulfilled promise": {
+				then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+					d := a.deferred()
+					a.later(func() { d.Resolve(sentinel) })
+					resolve(d.Promise)
+				}), fulfilledWith(sentinel)},
+			"resolvePromise was called with an asynchronously-rejected promise": {
+				then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+					d := a.deferred()
+					a.later(func() { d.Reject(errSentinel) })
+					resolve(d.Promise)
+				}), rejectedWith(errSentinel)},
+			"rejectPromise was called": {
+				then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+					reject(errSentinel)
+				}), rejectedWith(errSentinel)},
+			"resolvePromise then rejectPromise were called": {
+				then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+					resolve(sentinel)
+					reject(errOther)
+				}), fulfilledWith(sentinel)},
+			"rejectPromise then resolvePromise were called": {
+				then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+					reject(errSentinel)
+					resolve(other)
+				}), rejectedWith(errSentinel)},
+		}
+		for name, c := range cases {
+			c := c
+			t.Run(name, func(t *testing.T) {
+				testPromiseResolution(t, c.x, c.check)
+			})
+		}
+	})
+
+	t.Run("2.3.3.3.4.2: otherwise, reject promise with PanicError of e", func(t *testing.T) {
+		check := func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			return func() {
+				var panicErr *promise.PanicError
+				assert.True(t, errors.As(p.Err(), &panicErr))
+				assert.ErrorIs(t, p.Err(), errOther)
+			}
+		}
+		t.Run("straightforward case", func(t *testing.T) {
+			testPromiseResolution(t, then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {}), check)
+		})
+		t.Run("resolvePromise is called asynchronously before the panic", func(t *testing.T) {
+			testPromiseResolution(t, then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				a.later(func() { resolve(sentinel) })
+			}), check)
+		})
+		t.Run("rejectPromise is called asynchronously before the panic", func(t *testing.T) {
+			testPromiseResolution(t, then(func(a *adapter, resolve func(value interface{}), reject func(err error)) {
+				a.later(func() { reject(errSentinel) })
+			}), check)
+		})
+	})
+}
+
+/*
+	2.3.4: If x is not a thenable, fulfill promise with x
+ */
+func TestSpec2_3_4(t *testing.T) {
+
+	for name, value := range values {
+		value := value
+		t.Run(name, func(t *testing.T) {
+			testPromiseResolution(t, func(a *adapter) interface{} { return value() }, fulfilledWith(value()))
+		})
+	}
+}
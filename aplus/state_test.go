@@ -0,0 +1,98 @@
+package aplus
+
+import (
+	"testing"
+
+	promise "github.com/danevge/go-promise"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	2.1.2: When fulfilled, a promise must not transition to any other state
+ */
+func TestSpec2_1_2(t *testing.T) {
+
+	testFulfilled(t, dummy, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+		c := &calls{}
+		p.ThenAndCatch(nil, c.onRejected)
+		return func() {
+			assert.Equal(t, 0, c.count)
+			assert.Equal(t, promise.Success, p.State())
+		}
+	})
+
+	cases := map[string]func(a *adapter, d *promise.Deferred){
+		"trying to fulfill then immediately reject": func(a *adapter, d *promise.Deferred) {
+			d.Resolve(dummy)
+			d.Reject(errDummy)
+		},
+		"trying to fulfill then reject, delayed": func(a *adapter, d *promise.Deferred) {
+			a.later(func() {
+				d.Resolve(dummy)
+				d.Reject(errDummy)
+			})
+		},
+		"trying to fulfill immediately then reject delayed": func(a *adapter, d *promise.Deferred) {
+			d.Resolve(dummy)
+			a.later(func() { d.Reject(errDummy) })
+		},
+	}
+	for name, settle := range cases {
+		settle := settle
+		t.Run(name, func(t *testing.T) {
+			a := newAdapter(t)
+			d := a.deferred()
+			fulfilled, rejected := &calls{}, &calls{}
+			d.Promise.ThenAndCatch(fulfilled.onFulfilled, rejected.onRejected)
+			settle(a, d)
+			a.drain()
+			assert.Equal(t, 1, fulfilled.count)
+			assert.Equal(t, 0, rejected.count)
+		})
+	}
+}
+
+/*
+	2.1.3: When rejected, a promise must not transition to any other state
+ */
+func TestSpec2_1_3(t *testing.T) {
+
+	testRejected(t, errDummy, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+		c := &calls{}
+		p.Then(c.onFulfilled)
+		return func() {
+			assert.Equal(t, 0, c.count)
+			assert.Equal(t, promise.Rejected, p.State())
+		}
+	})
+
+	cases := map[string]func(a *adapter, d *promise.Deferred){
+		"trying to reject then immediately fulfill": func(a *adapter, d *promise.Deferred) {
+			d.Reject(errDummy)
+			d.Resolve(dummy)
+		},
+		"trying to reject then fulfill, delayed": func(a *adapter, d *promise.Deferred) {
+			a.later(func() {
+				d.Reject(errDummy)
+				d.Resolve(dummy)
+			})
+		},
+		"trying to reject immediately then fulfill delayed": func(a *adapter, d *promise.Deferred) {
+			d.Reject(errDummy)
+			a.later(func() { d.Resolve(dummy) })
+		},
+	}
+	for name, settle := range cases {
+		settle := settle
+		t.Run(name, func(t *testing.T) {
+			a := newAdapter(t)
+			d := a.deferred()
+			fulfilled, rejected := &calls{}, &calls{}
+			d.Promise.ThenAndCatch(fulfilled.onFulfilled, rejected.onRejected)
+			settle(a, d)
+			a.drain()
+			assert.Equal(t, 0, fulfilled.count)
+			assert.Equal(t, 1, rejected.count)
+		})
+	}
+}
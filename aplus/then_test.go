@@ -0,0 +1,511 @@
+package aplus
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	promise "github.com/danevge/go-promise"
+	"github.com/stretchr/testify/assert"
+)
+
+/*
+	2.2.1: Both onFulfilled and onRejected are optional arguments
+ */
+func TestSpec2_2_1(t *testing.T) {
+
+	t.Run("2.2.1.1: nil onFulfilled is ignored", func(t *testing.T) {
+		t.Run("applied to a directly-rejected promise", func(t *testing.T) {
+			a := newAdapter(t)
+			c := &calls{}
+			a.rejected(errDummy).ThenAndCatch(nil, c.onRejected)
+			a.drain()
+			assert.Equal(t, 1, c.count)
+		})
+		t.Run("applied to a promise rejected and then chained off of", func(t *testing.T) {
+			a := newAdapter(t)
+			c := &calls{}
+			a.rejected(errDummy).ThenAndCatch(func(value interface{}) interface{} { return nil }, nil).
+				ThenAndCatch(nil, c.onRejected)
+			a.drain()
+			assert.Equal(t, 1, c.count)
+		})
+	})
+	t.Run("2.2.1.2: nil onRejected is ignored", func(t *testing.T) {
+		t.Run("applied to a directly-fulfilled promise", func(t *testing.T) {
+			a := newAdapter(t)
+			c := &calls{}
+			a.resolved(dummy).ThenAndCatch(c.onFulfilled, nil)
+			a.drain()
+			assert.Equal(t, 1, c.count)
+		})
+		t.Run("applied to a promise fulfilled and then chained off of", func(t *testing.T) {
+			a := newAdapter(t)
+			c := &calls{}
+			a.resolved(dummy).ThenAndCatch(nil, func(err error) interface{} { return nil }).
+				ThenAndCatch(c.onFulfilled, nil)
+			a.drain()
+			assert.Equal(t, 1, c.count)
+		})
+	})
+}
+
+/*
+	2.2.2 and 2.2.3: onFulfilled is called once after fulfillment, onRejected is called once after rejection
+ */
+func TestSpec2_2_2(t *testing.T) {
+
+	t.Run("2.2.2.1: it must be called after promise is fulfilled, with promise's value", func(t *testing.T) {
+		testFulfilled(t, sentinel, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			c := &calls{}
+			p.Then(c.onFulfilled)
+			return func() {
+				assert.Equal(t, 1, c.count)
+				assert.Same(t, sentinel, c.value)
+			}
+		})
+	})
+
+	t.Run("2.2.2.2: it must not be called before promise is fulfilled", func(t *testing.T) {
+		t.Run("fulfilled after a delay", func(t *testing.T) {
+			a := newAdapter(t)
+			d := a.deferred()
+			c := &calls{}
+			d.Promise.Then(c.onFulfilled)
+			a.drain()
+			assert.Equal(t, 0, c.count)
+			d.Resolve(dummy)
+			a.drain()
+			assert.Equal(t, 1, c.count)
+		})
+		t.Run("never fulfilled", func(t *testing.T) {
+			a := newAdapter(t)
+			c := &calls{}
+			a.deferred().Promise.Then(c.onFulfilled)
+			a.drain()
+			assert.Equal(t, 0, c.count)
+		})
+	})
+
+	t.Run("2.2.2.3: it must not be called more than once", func(t *testing.T) {
+		for name, settle := range settleTwice(func(d *promise.Deferred) { d.Resolve(dummy) }) {
+			settle := settle
+			t.Run(name, func(t *testing.T) {
+				a := newAdapter(t)
+				d := a.deferred()
+				c := &calls{}
+				d.Promise.Then(c.onFulfilled)
+				settle(a, d)
+				a.drain()
+				assert.Equal(t, 1, c.count)
+			})
+		}
+		testHandlersSpacedApart(t, func(d *promise.Deferred) { d.Resolve(dummy) },
+			func(p *promise.Promise, c *calls) { p.Then(c.onFulfilled) })
+	})
+}
+
+func TestSpec2_2_3(t *testing.T) {
+
+	t.Run("2.2.3.1: it must be called after promise is rejected, with promise's reason", func(t *testing.T) {
+		testRejected(t, errSentinel, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			c := &calls{}
+			p.Catch(c.onRejected)
+			return func() {
+				assert.Equal(t, 1, c.count)
+				assert.Equal(t, errSentinel, c.err)
+			}
+		})
+	})
+
+	t.Run("2.2.3.2: it must not be called before promise is rejected", func(t *testing.T) {
+		t.Run("rejected after a delay", func(t *testing.T) {
+			a := newAdapter(t)
+			d := a.deferred()
+			c := &calls{}
+			d.Promise.Catch(c.onRejected)
+			a.drain()
+			assert.Equal(t, 0, c.count)
+			d.Reject(errDummy)
+			a.drain()
+			assert.Equal(t, 1, c.count)
+		})
+		t.Run("never rejected", func(t *testing.T) {
+			a := newAdapter(t)
+			c := &calls{}
+			a.deferred().Promise.Catch(c.onRejected)
+			a.drain()
+			assert.Equal(t, 0, c.count)
+		})
+	})
+
+	t.Run("2.2.3.3: it must not be called more than once", func(t *testing.T) {
+		for name, settle := range settleTwice(func(d *promise.Deferred) { d.Reject(errDummy) }) {
+			settle := settle
+			t.Run(name, func(t *testing.T) {
+				a := newAdapter(t)
+				d := a.deferred()
+				c := &calls{}
+				d.Promise.Catch(c.onRejected)
+				settle(a, d)
+				a.drain()
+				assert.Equal(t, 1, c.count)
+			})
+		}
+		testHandlersSpacedApart(t, func(d *promise.Deferred) { d.Reject(errDummy) },
+			func(p *promise.Promise, c *calls) { p.Catch(c.onRejected) })
+	})
+}
+
+/*
+	Ways to settle deferred more than once
+ */
+func settleTwice(settle func(d *promise.Deferred)) map[string]func(a *adapter, d *promise.Deferred) {
+
+	return map[string]func(a *adapter, d *promise.Deferred){
+		"already-settled": func(a *adapter, d *promise.Deferred) {
+			settle(d)
+			a.drain()
+		},
+		"trying to settle a pending promise more than once, immediately": func(a *adapter, d *promise.Deferred) {
+			settle(d)
+			settle(d)
+		},
+		"trying to settle a pending promise more than once, delayed": func(a *adapter, d *promise.Deferred) {
+			a.later(func() {
+				settle(d)
+				settle(d)
+			})
+		},
+		"trying to settle a pending promise more than once, immediately then delayed": func(a *adapter, d *promise.Deferred) {
+			settle(d)
+			a.later(func() { settle(d) })
+		},
+		"trying to fulfill and reject": func(a *adapter, d *promise.Deferred) {
+			settle(d)
+			d.Resolve(other)
+			d.Reject(errOther)
+		},
+	}
+}
+
+/*
+	Every handler is called once when they are added at different times
+ */
+func testHandlersSpacedApart(t *testing.T, settle func(d *promise.Deferred), add func(p *promise.Promise, c *calls)) {
+
+	t.Run("when multiple handlers are added, spaced apart in time", func(t *testing.T) {
+		a := newAdapter(t)
+		d := a.deferred()
+		c1, c2, c3 := &calls{}, &calls{}, &calls{}
+		add(d.Promise, c1)
+		a.drain()
+		add(d.Promise, c2)
+		a.drain()
+		add(d.Promise, c3)
+		settle(d)
+		a.drain()
+		assert.Equal(t, []int{1, 1, 1}, []int{c1.count, c2.count, c3.count})
+	})
+	t.Run("when handlers are interleaved with settlement", func(t *testing.T) {
+		a := newAdapter(t)
+		d := a.deferred()
+		c1, c2 := &calls{}, &calls{}
+		add(d.Promise, c1)
+		settle(d)
+		add(d.Promise, c2)
+		a.drain()
+		assert.Equal(t, []int{1, 1}, []int{c1.count, c2.count})
+	})
+}
+
+/*
+	2.2.4: onFulfilled or onRejected must not be called until the execution context stack contains only platform code
+ */
+func TestSpec2_2_4(t *testing.T) {
+
+	t.Run("then returns before the promise becomes fulfilled", func(t *testing.T) {
+		testFulfilled(t, dummy, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			returned, calledBefore := false, false
+			p.Then(func(value interface{}) interface{} {
+				calledBefore = !returned
+				return nil
+			})
+			returned = true
+			return func() { assert.False(t, calledBefore) }
+		})
+	})
+	t.Run("then returns before the promise becomes rejected", func(t *testing.T) {
+		testRejected(t, errDummy, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			returned, calledBefore := false, false
+			p.Catch(func(err error) interface{} {
+				calledBefore = !returned
+				return nil
+			})
+			returned = true
+			return func() { assert.False(t, calledBefore) }
+		})
+	})
+
+	type settleCase struct {
+		settle func(d *promise.Deferred)
+		add    func(p *promise.Promise, f func())
+		other  func(a *adapter) *promise.Promise
+	}
+	cases := map[string]settleCase{
+		"fulfillment": {
+			settle: func(d *promise.Deferred) { d.Resolve(dummy) },
+			add: func(p *promise.Promise, f func()) {
+				p.Then(func(value interface{}) interface{} { f(); return nil })
+			},
+			other: func(a *adapter) *promise.Promise { return a.resolved(dummy) },
+		},
+		"rejection": {
+			settle: func(d *promise.Deferred) { d.Reject(errDummy) },
+			add: func(p *promise.Promise, f func()) {
+				p.Catch(func(err error) interface{} { f(); return nil })
+			},
+			other: func(a *adapter) *promise.Promise { return a.rejected(errDummy) },
+		},
+	}
+	for name, c := range cases {
+		c := c
+		t.Run("clean-stack execution ordering tests ("+name+" case)", func(t *testing.T) {
+			t.Run("when the handler is added immediately before the promise is settled", func(t *testing.T) {
+				a := newAdapter(t)
+				d := a.deferred()
+				called := false
+				c.add(d.Promise, func() { called = true })
+				c.settle(d)
+				assert.False(t, called)
+				a.drain()
+				assert.True(t, called)
+			})
+			t.Run("when the handler is added immediately after the promise is settled", func(t *testing.T) {
+				a := newAdapter(t)
+				d := a.deferred()
+				called := false
+				c.settle(d)
+				c.add(d.Promise, func() { called = true })
+				assert.False(t, called)
+				a.drain()
+				assert.True(t, called)
+			})
+			t.Run("when one handler is added inside another handler", func(t *testing.T) {
+				a := newAdapter(t)
+				p := c.other(a)
+				firstFinished, secondAfterFirst := false, false
+				c.add(p, func() {
+					c.add(p, func() { secondAfterFirst = firstFinished })
+					firstFinished = true
+				})
+				a.drain()
+				assert.True(t, secondAfterFirst)
+			})
+			t.Run("when the handler is added inside a handler of other kind", func(t *testing.T) {
+				a := newAdapter(t)
+				p := c.other(a)
+				firstFinished, secondAfterFirst := false, false
+				a.resolved(dummy).Then(func(value interface{}) interface{} {
+					c.add(p, func() { secondAfterFirst = firstFinished })
+					firstFinished = true
+					return nil
+				})
+				a.drain()
+				assert.True(t, secondAfterFirst)
+			})
+			t.Run("when the promise is settled asynchronously", func(t *testing.T) {
+				a := newAdapter(t)
+				d := a.deferred()
+				stackFinished, calledAfter := false, false
+				a.later(func() {
+					c.settle(d)
+					stackFinished = true
+				})
+				c.add(d.Promise, func() { calledAfter = stackFinished })
+				a.drain()
+				assert.True(t, calledAfter)
+			})
+		})
+	}
+}
+
+/*
+	2.2.6: then may be called multiple times on the same promise
+ */
+func TestSpec2_2_6(t *testing.T) {
+
+	t.Run("2.2.6.1: if/when promise is fulfilled, all respective onFulfilled callbacks must execute in order", func(t *testing.T) {
+		testMultipleHandlers(t, func(t *testing.T, test promiseTest) { testFulfilled(t, sentinel, test) },
+			func(p *promise.Promise, f func(value interface{}, err error) interface{}) *promise.Promise {
+				return p.Then(func(value interface{}) interface{} { return f(value, nil) })
+			},
+			func(t *testing.T, value interface{}, err error) { assert.Same(t, sentinel, value) })
+	})
+	t.Run("2.2.6.2: if/when promise is rejected, all respective onRejected callbacks must execute in order", func(t *testing.T) {
+		testMultipleHandlers(t, func(t *testing.T, test promiseTest) { testRejected(t, errSentinel, test) },
+			func(p *promise.Promise, f func(value interface{}, err error) interface{}) *promise.Promise {
+				return p.Catch(func(err error) interface{} { return f(nil, err) })
+			},
+			func(t *testing.T, value interface{}, err error) { assert.Equal(t, errSentinel, err) })
+	})
+}
+
+func testMultipleHandlers(t *testing.T, run func(t *testing.T, test promiseTest),
+	add func(p *promise.Promise, f func(value interface{}, err error) interface{}) *promise.Promise,
+	check func(t *testing.T, value interface{}, err error)) {
+
+	t.Run("multiple boring handlers", func(t *testing.T) {
+		run(t, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			count := 0
+			for i := 0; i < 3; i++ {
+				add(p, func(value interface{}, err error) interface{} {
+					check(t, value, err)
+					count++
+					return other
+				})
+			}
+			return func() { assert.Equal(t, 3, count) }
+		})
+	})
+	t.Run("multiple handlers, one of which panics", func(t *testing.T) {
+		run(t, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			count := 0
+			for i := 0; i < 3; i++ {
+				i := i
+				add(p, func(value interface{}, err error) interface{} {
+					count++
+					if i == 1 {
+						panic(other)
+					}
+					return other
+				})
+			}
+			return func() { assert.Equal(t, 3, count) }
+		})
+	})
+	t.Run("results in multiple branching chains with their own values", func(t *testing.T) {
+		run(t, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			results := make([]interface{}, 3)
+			for i, result := range []interface{}{"1", errOther, "3"} {
+				i, result := i, result
+				add(p, func(value interface{}, err error) interface{} { return result }).
+					ThenAndCatch(
+						func(value interface{}) interface{} { results[i] = value; return nil },
+						func(err error) interface{} { results[i] = err; return nil })
+			}
+			return func() { assert.Equal(t, []interface{}{"1", errOther, "3"}, results) }
+		})
+	})
+	t.Run("handlers are called in the original order", func(t *testing.T) {
+		run(t, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			var order []int
+			for i := 0; i < 3; i++ {
+				i := i
+				add(p, func(value interface{}, err error) interface{} { order = append(order, i); return nil })
+			}
+			return func() { assert.Equal(t, []int{0, 1, 2}, order) }
+		})
+	})
+	t.Run("even when one handler is added inside another handler", func(t *testing.T) {
+		run(t, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+			var order []int
+			add(p, func(value interface{}, err error) interface{} { order = append(order, 0); return nil })
+			add(p, func(value interface{}, err error) interface{} {
+				order = append(order, 1)
+				add(p, func(value interface{}, err error) interface{} { order = append(order, 3); return nil })
+				return nil
+			})
+			add(p, func(value interface{}, err error) interface{} { order = append(order, 2); return nil })
+			return func() { assert.Equal(t, []int{0, 1, 2, 3}, order) }
+		})
+	})
+}
+
+/*
+	2.2.7: then must return a promise
+ */
+func TestSpec2_2_7(t *testing.T) {
+
+	t.Run("is a promise", func(t *testing.T) {
+		a := newAdapter(t)
+		p := a.resolved(dummy)
+		p2 := p.Then(nil)
+		assert.NotNil(t, p2)
+		assert.NotSame(t, p, p2)
+		assert.NotSame(t, p, p.Catch(nil))
+	})
+
+	t.Run("2.2.7.2: if either handler panics or returns error e, promise2 must be rejected with e", func(t *testing.T) {
+		for name, reason := range reasons {
+			reason := reason
+			t.Run(name, func(t *testing.T) {
+				testFulfilled(t, dummy, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+					panicked := p.Then(func(value interface{}) interface{} { panic(reason()) })
+					returned := p.Then(func(value interface{}) interface{} { return reason() })
+					return func() {
+						assertPanic(t, reason(), panicked)
+						assert.Equal(t, reason().Error(), returned.Err().Error())
+					}
+				})
+				testRejected(t, errDummy, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+					panicked := p.Catch(func(err error) interface{} { panic(reason()) })
+					returned := p.Catch(func(err error) interface{} { return reason() })
+					return func() {
+						assertPanic(t, reason(), panicked)
+						assert.Equal(t, reason().Error(), returned.Err().Error())
+					}
+				})
+			})
+		}
+	})
+
+	t.Run("2.2.7.3: if onFulfilled is nil and promise1 is fulfilled, promise2 must be fulfilled with the same value", func(t *testing.T) {
+		for name, value := range values {
+			value := value
+			t.Run(name, func(t *testing.T) {
+				testFulfilled(t, value(), func(t *testing.T, a *adapter, p *promise.Promise) func() {
+					p2 := p.ThenAndCatch(nil, func(err error) interface{} { return other })
+					return func() {
+						assert.Equal(t, promise.Success, p2.State())
+						assertSameValue(t, value(), p2.Value())
+					}
+				})
+			})
+		}
+	})
+
+	t.Run("2.2.7.4: if onRejected is nil and promise1 is rejected, promise2 must be rejected with the same reason", func(t *testing.T) {
+		for name, reason := range reasons {
+			reason := reason
+			t.Run(name, func(t *testing.T) {
+				r := reason()
+				testRejected(t, r, func(t *testing.T, a *adapter, p *promise.Promise) func() {
+					p2 := p.ThenAndCatch(func(value interface{}) interface{} { return other }, nil)
+					return func() { assert.True(t, p2.Err() == r) }
+				})
+			})
+		}
+	})
+}
+
+func assertPanic(t *testing.T, reason error, p *promise.Promise) {
+
+	var panicErr *promise.PanicError
+	assert.True(t, errors.As(p.Err(), &panicErr))
+	if panicErr != nil {
+		assert.Equal(t, reason.Error(), panicErr.Value.(error).Error())
+	}
+}
+
+/*
+	Values are equal, func values are compared by type
+ */
+func assertSameValue(t *testing.T, expected, actual interface{}) {
+
+	if _, ok := expected.(func()); ok {
+		assert.IsType(t, expected, actual)
+		return
+	}
+	assert.Equal(t, fmt.Sprintf("%#v", expected), fmt.Sprintf("%#v", actual))
+}
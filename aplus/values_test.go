@@ -0,0 +1,129 @@
+package aplus
+
+import (
+	"errors"
+	"fmt"
+
+	promise "github.com/danevge/go-promise"
+)
+
+type valueError struct {
+	message string
+}
+
+func (e valueError) Error() string {
+	return e.message
+}
+
+type pointerError struct{}
+
+func (e *pointerError) Error() string {
+	return "pointer error"
+}
+
+/*
+	Error which is thenable too, reason mustn't be adopted
+ */
+type thenableError struct{}
+
+func (thenableError) Error() string {
+	return "thenable error"
+}
+
+func (thenableError) Then(resolve func(value interface{}), reject func(err error)) {
+}
+
+/*
+	Fulfillment values which aren't thenables, like 2.3.4 of Promises/A+
+ */
+var values = map[string]func() interface{}{
+	"nil":     func() interface{} { return nil },
+	"false":   func() interface{} { return false },
+	"true":    func() interface{} { return true },
+	"zero":    func() interface{} { return 0 },
+	"number":  func() interface{} { return 5 },
+	"string":  func() interface{} { return "string" },
+	"pointer": func() interface{} { return &dummyValue{dummy: "pointer"} },
+	"struct":  func() interface{} { return dummyValue{dummy: "struct"} },
+	"slice":   func() interface{} { return []int{1, 2} },
+	"map":     func() interface{} { return map[string]int{"a": 1} },
+	"func":    func() interface{} { return func() {} },
+}
+
+/*
+	Rejection reasons, like reasons.js of Promises/A+
+ */
+var reasons = map[string]func() error{
+	"errors.New":                   func() error { return errors.New("reason") },
+	"wrapped error":                func() error { return fmt.Errorf("wrapped: %w", errSentinel) },
+	"value error":                  func() error { return valueError{message: "value"} },
+	"pointer error":                func() error { return &pointerError{} },
+	"timeout error":                func() error { return &promise.TimeoutError{Id: "timeout"} },
+	"aggregate error":              func() error { return &promise.AggregateError{Id: "any", Errors: []error{errOther}} },
+	"an always-pending thenable":   func() error { return thenableError{} },
+}
+
+/*
+	Thenables which fulfill by value, like thenables.js of Promises/A+
+ */
+var fulfilledThenables = map[string]func(a *adapter, value interface{}) interface{}{
+	"a synchronously-fulfilled custom thenable": func(a *adapter, value interface{}) interface{} {
+		return thenable(func(resolve func(value interface{}), reject func(err error)) {
+			resolve(value)
+		})
+	},
+	"an asynchronously-fulfilled custom thenable": func(a *adapter, value interface{}) interface{} {
+		return thenable(func(resolve func(value interface{}), reject func(err error)) {
+			a.later(func() { resolve(value) })
+		})
+	},
+	"a thenable that tries to fulfill twice": func(a *adapter, value interface{}) interface{} {
+		return thenable(func(resolve func(value interface{}), reject func(err error)) {
+			resolve(value)
+			resolve(other)
+		})
+	},
+	"a thenable that fulfills but then panics": func(a *adapter, value interface{}) interface{} {
+		return thenable(func(resolve func(value interface{}), reject func(err error)) {
+			resolve(value)
+			panic(other)
+		})
+	},
+	"an already-fulfilled promise": func(a *adapter, value interface{}) interface{} {
+		return a.resolved(value)
+	},
+	"an eventually-fulfilled promise": func(a *adapter, value interface{}) interface{} {
+		d := a.deferred()
+		a.later(func() { d.Resolve(value) })
+		return d.Promise
+	},
+}
+
+/*
+	Thenables which reject by reason
+ */
+var rejectedThenables = map[string]func(a *adapter, reason error) interface{}{
+	"a synchronously-rejected custom thenable": func(a *adapter, reason error) interface{} {
+		return thenable(func(resolve func(value interface{}), reject func(err error)) {
+			reject(reason)
+		})
+	},
+	"an asynchronously-rejected custom thenable": func(a *adapter, reason error) interface{} {
+		return thenable(func(resolve func(value interface{}), reject func(err error)) {
+			a.later(func() { reject(reason) })
+		})
+	},
+	"a thenable that immediately panics in then": func(a *adapter, reason error) interface{} {
+		return thenable(func(resolve func(value interface{}), reject func(err error)) {
+			panic(reason)
+		})
+	},
+	"an already-rejected promise": func(a *adapter, reason error) interface{} {
+		return a.rejected(reason)
+	},
+	"an eventually-rejected promise": func(a *adapter, reason error) interface{} {
+		d := a.deferred()
+		a.later(func() { d.Reject(reason) })
+		return d.Promise
+	},
+}
@@ -0,0 +1,217 @@
+package go_promise
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+/*
+	Source of time for every timeout, delay and retry of package
+
+	RealClock is used by default, FakeClock is for deterministic tests.
+ */
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+/*
+	Timer of Clock.AfterFunc
+ */
+type Timer interface {
+	// false if timer has already fired or been stopped
+	Stop() bool
+}
+
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+/*
+	Clock which is changed only by Advance
+
+	Timers are fired by Advance in goroutine of caller, that's why
+	all handlers of AfterFunc are finished when Advance returns.
+	Timer with not positive duration is fired immediately in goroutine
+	of caller of After or AfterFunc.
+
+	Use:
+		clock := NewFakeClock(time.Now())
+		promise := NewPromiseWithContext(WithClock(ctx, clock), ...).Timeout(time.Second)
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+ */
+type FakeClock struct {
+	mutex   sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock *FakeClock
+	at    time.Time
+	fire  func(now time.Time)
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+
+	clock := &FakeClock{now: now}
+	clock.changed = sync.NewCond(&clock.mutex)
+	return clock
+}
+
+func (c *FakeClock) Now() time.Time {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+
+	// buffered, because nobody can read it
+	ch := make(chan time.Time, 1)
+	c.add(d, func(now time.Time) { ch <- now })
+	return ch
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+
+	return c.add(d, func(now time.Time) { f() })
+}
+
+/*
+	Move time forward and fire timers in order of their time
+ */
+func (c *FakeClock) Advance(d time.Duration) {
+
+	c.mutex.Lock()
+	c.now = c.now.Add(d)
+	now := c.now
+	var fired []*fakeTimer
+	for len(c.timers) > 0 && !c.timers[0].at.After(now) {
+		fired = append(fired, c.timers[0])
+		c.timers = c.timers[1:]
+	}
+	c.changed.Broadcast()
+	c.mutex.Unlock()
+
+	for _, timer := range fired {
+		timer.fire(now)
+	}
+}
+
+/*
+	Wait until count of not fired timers is at least n
+
+	Goroutines create timers asynchronously, so test must wait them before Advance.
+ */
+func (c *FakeClock) BlockUntil(n int) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for len(c.timers) < n {
+		c.changed.Wait()
+	}
+}
+
+/*
+	Count of not fired timers
+ */
+func (c *FakeClock) Timers() int {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+func (c *FakeClock) add(d time.Duration, fire func(now time.Time)) *fakeTimer {
+
+	c.mutex.Lock()
+	now := c.now
+	timer := &fakeTimer{clock: c, at: now.Add(d), fire: fire}
+	if d <= 0 {
+		c.mutex.Unlock()
+		fire(now)
+		return timer
+	}
+	// stable order for timers with the same time
+	i := sort.Search(len(c.timers), func(i int) bool { return c.timers[i].at.After(timer.at) })
+	c.timers = append(c.timers, nil)
+	copy(c.timers[i+1:], c.timers[i:])
+	c.timers[i] = timer
+	c.changed.Broadcast()
+	c.mutex.Unlock()
+	return timer
+}
+
+func (t *fakeTimer) Stop() bool {
+
+	c := t.clock
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for i, timer := range c.timers {
+		if timer == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.changed.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+type clockKey struct{}
+
+var clock = struct {
+	sync.RWMutex
+	value Clock
+}{value: RealClock}
+
+/*
+	Set package-wide clock, it's used by chains without own clock
+
+	nil sets RealClock.
+ */
+func SetClock(c Clock) {
+
+	if c == nil {
+		c = RealClock
+	}
+	clock.Lock()
+	defer clock.Unlock()
+	clock.value = c
+}
+
+/*
+	Clock for chain created by NewPromiseWithContext
+ */
+func WithClock(ctx context.Context, c Clock) context.Context {
+
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+func clockFrom(ctx context.Context) Clock {
+
+	if c, ok := ctx.Value(clockKey{}).(Clock); ok && c != nil {
+		return c
+	}
+	clock.RLock()
+	defer clock.RUnlock()
+	return clock.value
+}
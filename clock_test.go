@@ -0,0 +1,120 @@
+package go_promise
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClockAfter(t *testing.T) {
+
+	start := time.Now()
+	clock := NewFakeClock(start)
+	ch := clock.After(100 * time.Millisecond)
+
+	clock.Advance(99 * time.Millisecond)
+	select {
+	case <-ch:
+		assert.Fail(t, "timer mustn't be fired")
+	default:
+	}
+
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, start.Add(100*time.Millisecond), <-ch)
+	assert.Equal(t, 0, clock.Timers())
+}
+
+func TestFakeClockAfterFuncOrder(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	order := make([]int, 0)
+	clock.AfterFunc(300*time.Millisecond, func() { order = append(order, 3) })
+	clock.AfterFunc(100*time.Millisecond, func() { order = append(order, 1) })
+	clock.AfterFunc(200*time.Millisecond, func() { order = append(order, 2) })
+	stopped := clock.AfterFunc(200*time.Millisecond, func() { order = append(order, 4) })
+
+	assert.True(t, stopped.Stop())
+	assert.False(t, stopped.Stop())
+	clock.Advance(time.Second)
+
+	assert.Equal(t, []int{1, 2, 3}, order)
+}
+
+func TestFakeClockFiresExpiredTimerImmediately(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	ctx := WithClock(context.Background(), clock)
+	fired := false
+	clock.AfterFunc(0, func() { fired = true })
+	assert.True(t, fired)
+	assert.Equal(t, 0, clock.Timers())
+
+	deadline := NewDeferredWithContext(ctx).Promise.WithDeadline(clock.Now().Add(-time.Second))
+	assert.Equal(t, Rejected, deadline.State())
+
+	SetClock(clock)
+	defer SetClock(nil)
+	assert.Equal(t, Success, Delay(0, testStr1).State())
+}
+
+func TestFakeClockGetWithTimeout(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	ctx := WithClock(context.Background(), clock)
+	release := make(chan bool)
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		<-release
+		return testStr1
+	})
+
+	result := make(chan error)
+	go func() {
+		_, err := promise.GetWithTimeout(time.Hour)
+		result <- err
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+
+	assert.ErrorIs(t, <-result, ErrTimeout)
+	close(release)
+}
+
+func TestFakeClockGetDoesNotKeepTimers(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	ctx := WithClock(context.Background(), clock)
+	deferred := NewDeferredWithContext(ctx)
+
+	result := make(chan interface{})
+	go func() {
+		value, _ := deferred.Promise.GetWithTimeout(time.Hour)
+		result <- value
+	}()
+	clock.BlockUntil(1)
+	deferred.Resolve(testStr1)
+	assert.Equal(t, testStr1, <-result)
+
+	for i := 0; i < 5; i++ {
+		deferred.Promise.Get()
+	}
+	assert.Equal(t, 0, clock.Timers())
+}
+
+func TestFakeClockTimeout(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	ctx := WithClock(context.Background(), clock)
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		<-ctx.Done()
+		return ctx.Err()
+	}).Timeout(time.Minute)
+
+	assert.Equal(t, 1, clock.Timers())
+	clock.Advance(time.Minute)
+
+	// timer is fired by Advance, promise is already rejected
+	assert.Equal(t, Rejected, promise.State())
+	assert.ErrorIs(t, promise.Err(), ErrTimeout)
+}
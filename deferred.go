@@ -0,0 +1,68 @@
+package go_promise
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+	Promise which is settled from outside by Resolve or Reject
+
+	Resolve and Reject can be called from any goroutine, only first call
+	settles promise, next calls are ignored.
+	Value of Resolve is handled like result of handler: error rejects promise,
+	promise is awaited.
+
+	Use:
+		deferred := NewDeferred()
+		conn.OnMessage(func(msg Message) { deferred.Resolve(msg) })
+		deferred.Promise.Then(...)
+ */
+type Deferred struct {
+	Promise *Promise
+	once    sync.Once
+}
+
+func NewDeferred() *Deferred {
+
+	return &Deferred{Promise: newPromise(nil)}
+}
+
+/*
+	Deferred of chain with context, cancellation of ctx rejects pending promise
+ */
+func NewDeferredWithContext(ctx context.Context) *Deferred {
+
+	return &Deferred{Promise: newContextPromise(ctx, nil)}
+}
+
+/*
+	JS example: const { promise, resolve, reject } = Promise.withResolvers();
+ */
+func WithResolvers() (promise *Promise, resolve func(value interface{}), reject func(err error)) {
+
+	deferred := NewDeferred()
+	return deferred.Promise, deferred.Resolve, deferred.Reject
+}
+
+func (d *Deferred) Resolve(value interface{}) {
+
+	d.once.Do(func() {
+		d.Promise.log(LevelDebug, "resolve deferred")
+		d.Promise.settleWith(value)
+	})
+}
+
+/*
+	nil error rejects promise by ErrNilReason
+ */
+func (d *Deferred) Reject(err error) {
+
+	if err == nil {
+		err = ErrNilReason
+	}
+	d.once.Do(func() {
+		d.Promise.log(LevelDebug, "reject deferred")
+		d.Promise.postProcess(&result{resultType: ERROR, err: err})
+	})
+}
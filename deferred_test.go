@@ -0,0 +1,108 @@
+package go_promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeferredResolve(t *testing.T) {
+
+	deferred := NewDeferred()
+	child := deferred.Promise.Then(func(d interface{}) interface{} { return d.(string) + testStr2 })
+	assert.Equal(t, Pending, deferred.Promise.State())
+
+	go deferred.Resolve(testStr1)
+	value, err := child.Await()
+
+	assert.Equal(t, testStr1+testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestDeferredReject(t *testing.T) {
+
+	promise, _, reject := WithResolvers()
+	caught := promise.Catch(func(err error) interface{} { return err.Error() + testStr2 })
+
+	reject(fmt.Errorf(testErr1))
+	value, err := caught.Await()
+
+	assert.Equal(t, testErr1+testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestDeferredRejectByNil(t *testing.T) {
+
+	promise, _, reject := WithResolvers()
+	reject(nil)
+	_, err := promise.Await()
+
+	assert.ErrorIs(t, err, ErrNilReason)
+}
+
+func TestDeferredOnlyFirstCall(t *testing.T) {
+
+	promise, resolve, reject := WithResolvers()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); resolve(testStr1) }()
+		go func() { defer wg.Done(); reject(fmt.Errorf(testErr1)) }()
+	}
+	wg.Wait()
+	value, err := promise.Await()
+	resolve(testStr2)
+
+	if err == nil {
+		assert.Equal(t, testStr1, value)
+	} else {
+		assert.EqualError(t, err, testErr1)
+	}
+	assert.Equal(t, value, promise.Value())
+}
+
+func TestDeferredResolveByPromise(t *testing.T) {
+
+	inner, resolveInner, _ := WithResolvers()
+	promise, resolve, _ := WithResolvers()
+
+	resolve(inner)
+	resolveInner(testStr1)
+	value, err := promise.Await()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestDeferredInAll(t *testing.T) {
+
+	first, second := NewDeferred(), NewDeferred()
+	all := All(
+		func(d interface{}) interface{} { return first.Promise },
+		func(d interface{}) interface{} { return second.Promise })
+
+	second.Resolve(testStr2)
+	first.Resolve(testStr1)
+	value, err := all.Await()
+
+	assert.Equal(t, []interface{}{testStr1, testStr2}, value)
+	assert.NoError(t, err)
+}
+
+func TestDeferredCancelledContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	deferred := NewDeferredWithContext(ctx)
+
+	cancel()
+	_, err := deferred.Promise.Await()
+	deferred.Resolve(testStr1)
+
+	var cancelled *CancelledError
+	assert.True(t, errors.As(err, &cancelled))
+}
@@ -0,0 +1,71 @@
+package go_promise
+
+import "time"
+
+/*
+	Create promise which is resolved by value after delay
+
+	Value can be error or promise like result of handler.
+	Timer of clock is used instead of goroutine, Cancel stops it.
+ */
+func Delay(delay time.Duration, value interface{}) *Promise {
+
+	promise := newPromise(nil)
+	promise.resolveAfter(delay, value)
+	return promise
+}
+
+/*
+	Create promise which is resolved by time t when it comes
+ */
+func After(t time.Time) *Promise {
+
+	promise := newPromise(nil)
+	promise.resolveAfter(t.Sub(promise.clock.Now()), t)
+	return promise
+}
+
+/*
+	Add new promise which is resolved by value of current promise after delay
+
+	Error of current promise is transferred without delay.
+ */
+func (p *Promise) Delay(delay time.Duration) *Promise {
+
+	promise := newPromise(p)
+	p.hookChildAttached(promise)
+	p.subscribe(func() {
+		r := p.result.copy()
+		if r.resultType == ERROR {
+			promise.finalize(Rejected, r)
+			return
+		}
+		promise.resolveAfter(delay, r.value)
+	})
+	return promise
+}
+
+func (p *Promise) resolveAfter(delay time.Duration, value interface{}) {
+
+	if p.logEnabled(LevelDebug) {
+		p.log(LevelDebug, "resolve after delay", Field{Key: "delay", Value: delay})
+	}
+	timer := p.clock.AfterFunc(delay, func() { p.settleWith(value) })
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.state != Pending {
+		// cancelled before start of timer
+		timer.Stop()
+		return
+	}
+	p.timer = timer
+}
+
+/*
+	Settle promise by value like result of handler
+ */
+func (p *Promise) settleWith(value interface{}) {
+
+	p.postProcess(resolve(value))
+}
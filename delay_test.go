@@ -0,0 +1,101 @@
+package go_promise
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelay(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	promise := Delay(time.Second, testStr1)
+	clock.Advance(999 * time.Millisecond)
+	assert.Equal(t, Pending, promise.State())
+
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, Success, promise.State())
+	assert.Equal(t, testStr1, promise.Value())
+}
+
+func TestDelayByError(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	promise := Delay(time.Second, fmt.Errorf(testErr1))
+	clock.Advance(time.Second)
+
+	assert.Equal(t, Rejected, promise.State())
+	assert.EqualError(t, promise.Err(), testErr1)
+}
+
+func TestDelayByPromise(t *testing.T) {
+
+	value, err := Delay(10*time.Millisecond, Resolve(testStr1)).Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestDelayCancel(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	promise := Delay(time.Second, testStr1)
+	child := promise.Then(func(d interface{}) interface{} { return testStr2 })
+	assert.Equal(t, 1, clock.Timers())
+
+	promise.Cancel()
+	assert.Equal(t, 0, clock.Timers())
+
+	_, err := child.Get()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.IsType(t, &CancelledError{}, err)
+}
+
+func TestAfter(t *testing.T) {
+
+	start := time.Now()
+	clock := NewFakeClock(start)
+	SetClock(clock)
+	defer SetClock(nil)
+
+	promise := After(start.Add(time.Minute))
+	clock.Advance(time.Minute)
+
+	assert.Equal(t, start.Add(time.Minute), promise.Value())
+}
+
+func TestPromiseDelay(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	ctx := WithClock(context.Background(), clock)
+
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 }).
+		Delay(time.Second)
+	clock.BlockUntil(1)
+	assert.Equal(t, Pending, promise.State())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, testStr1, promise.Value())
+}
+
+func TestPromiseDelayByError(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).
+		Delay(time.Hour).
+		Get()
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr1)
+}
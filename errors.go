@@ -0,0 +1,219 @@
+package go_promise
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+var (
+	// all timeout errors match it by errors.Is
+	ErrTimeout = errors.New("timeout error")
+	// Any doesn't have success promises
+	ErrNoSuccess = errors.New("not success promises")
+	// reject of Deferred or Thenable is called with nil error
+	ErrNilReason = errors.New("promise is rejected with nil error")
+	// promise resolves to itself, like TypeError of JS
+	ErrChainingCycle = errors.New("chaining cycle detected for promise")
+	// handler returns nil *Promise or Promise which isn't created by constructor
+	ErrInvalidPromise = errors.New("invalid promise")
+)
+
+/*
+	Promise isn't settled in time
+
+	Id - id of promise
+	Timeout - waiting time
+	rejectedId - id of promise rejected by Timeout, empty for GetWithTimeout
+ */
+type TimeoutError struct {
+	Id         string
+	Timeout    time.Duration
+	rejectedId string
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("promise %v: timeout error after %v", e.Id, e.Timeout)
+}
+
+func (e *TimeoutError) Unwrap() error {
+
+	return ErrTimeout
+}
+
+/*
+	Rejection reason for promise of cancelled chain
+
+	Id - id of promise
+	Cause - context.Canceled or context.DeadlineExceeded
+ */
+type CancelledError struct {
+	Id    string
+	Cause error
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("promise %v: %v", e.Id, e.Cause)
+}
+
+func (e *CancelledError) Unwrap() error {
+
+	return e.Cause
+}
+
+/*
+	Rejection reason for promise which resolves to itself
+	directly or by chain of promises resolved to each other
+
+	Id - id of promise
+	Chain - ids of promises, the first id is the same as the last one
+ */
+type CycleError struct {
+	Id    string
+	Chain []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("promise %v: %v: %v", e.Id, ErrChainingCycle, strings.Join(e.Chain, " -> "))
+}
+
+func (e *CycleError) Unwrap() error {
+
+	return ErrChainingCycle
+}
+
+/*
+	Rejection reason for promise whose all inner promises are rejected
+
+	Id - id of promise
+	Errors - errors of inner promises
+ */
+type AggregateError struct {
+	Id     string
+	Errors []error
+}
+
+func (e *AggregateError) Error() string {
+
+	messages := make([]string, len(e.Errors), len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("promise %v: %v: [%v]", e.Id, ErrNoSuccess, strings.Join(messages, "; "))
+}
+
+/*
+	AggregateError is ErrNoSuccess
+ */
+func (e *AggregateError) Is(target error) bool {
+
+	return target == ErrNoSuccess
+}
+
+/*
+	errors.Is and errors.As check all inner errors
+ */
+func (e *AggregateError) Unwrap() []error {
+
+	return e.Errors
+}
+
+/*
+	Rejection reason for promise whose handler panicked
+
+	Id - id of promise
+	Value - recovered value
+	Stack - stack trace of handler goroutine
+ */
+type PanicError struct {
+	Id    string
+	Value interface{}
+	Stack []byte
+}
+
+func newPanicError(id string, value interface{}) *PanicError {
+
+	return &PanicError{
+		Id:    id,
+		Value: value,
+		Stack: debug.Stack(),
+	}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("promise %v: panic: %v", e.Id, e.Value)
+}
+
+/*
+	panic(err) can be checked by errors.Is and errors.As
+ */
+func (e *PanicError) Unwrap() error {
+
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+/*
+	Rejection reason for AllLimitCollect and MapCollect
+
+	Id - id of promise
+	Errors - errors in input order, nil for success items
+ */
+type BatchError struct {
+	Id     string
+	Errors []error
+}
+
+func (e *BatchError) Error() string {
+
+	messages := make([]string, 0, len(e.Errors))
+	for i, err := range e.Errors {
+		if err != nil {
+			messages = append(messages, fmt.Sprintf("%d: %v", i, err))
+		}
+	}
+	return fmt.Sprintf("promise %v: %d of %d failed: [%v]",
+		e.Id, len(messages), len(e.Errors), strings.Join(messages, "; "))
+}
+
+/*
+	errors.Is and errors.As check all not nil errors
+ */
+func (e *BatchError) Unwrap() []error {
+
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+/*
+	Rejection reason for Retry
+
+	Id - id of promise
+	Errors - errors of all attempts
+ */
+type RetryError struct {
+	Id     string
+	Errors []error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("promise %v: %d attempts failed, last error: %v",
+		e.Id, len(e.Errors), e.Errors[len(e.Errors)-1])
+}
+
+/*
+	errors.Is and errors.As check errors of all attempts
+ */
+func (e *RetryError) Unwrap() []error {
+
+	return e.Errors
+}
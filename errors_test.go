@@ -0,0 +1,109 @@
+package go_promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanicInHandler(t *testing.T) {
+
+	promise := NewPromise(func(d interface{}) interface{} { panic(testStr1) })
+	value, err := promise.Get()
+
+	assert.Equal(t, nil, value)
+	var panicErr *PanicError
+	assert.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, testStr1, panicErr.Value)
+	assert.Equal(t, promise.id, panicErr.Id)
+	assert.Contains(t, string(panicErr.Stack), "errors_test.go")
+}
+
+func TestPanicByErrorInHandler(t *testing.T) {
+
+	cause := fmt.Errorf(testErr1)
+	_, err := NewPromise(func(d interface{}) interface{} { panic(cause) }).Get()
+
+	assert.True(t, errors.Is(err, cause))
+}
+
+func TestPanicInThenTransferToCatch(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).
+		Then(func(d interface{}) interface{} { panic(testStr2) }).
+		Catch(func(err error) interface{} {
+		if _, ok := err.(*PanicError); ok {
+			return testStr3
+		}
+		return err
+	}).Get()
+
+	assert.Equal(t, testStr3, value)
+	assert.NoError(t, err)
+}
+
+func TestPanicInCatch(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).
+		ThenAndCatch(
+		func(d interface{}) interface{} { return testStr2 },
+		func(err error) interface{} { panic(testStr3) }).
+		Get()
+
+	assert.Equal(t, nil, value)
+	assert.IsType(t, &PanicError{}, err)
+}
+
+func TestTimeoutErrorType(t *testing.T) {
+
+	promise := NewPromise(func(d interface{}) interface{} {
+		time.Sleep(100 * time.Millisecond)
+		return testStr1
+	})
+	_, err := promise.GetWithTimeout(10 * time.Millisecond)
+
+	var timeoutErr *TimeoutError
+	assert.True(t, errors.As(err, &timeoutErr))
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.Equal(t, promise.id, timeoutErr.Id)
+	assert.Equal(t, 10*time.Millisecond, timeoutErr.Timeout)
+}
+
+func TestCancelledErrorType(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 })
+	_, err := promise.Get()
+
+	var cancelledErr *CancelledError
+	assert.True(t, errors.As(err, &cancelledErr))
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.False(t, errors.Is(err, ErrTimeout))
+	assert.Equal(t, promise.id, cancelledErr.Id)
+}
+
+func TestAggregateErrorType(t *testing.T) {
+
+	cause := fmt.Errorf(testErr1)
+	err := error(&AggregateError{Id: "id", Errors: []error{cause, fmt.Errorf(testErr2)}})
+
+	assert.True(t, errors.Is(err, ErrNoSuccess))
+	assert.True(t, errors.Is(err, cause))
+	assert.False(t, errors.Is(err, ErrTimeout))
+	assert.Equal(t, "promise id: not success promises: [ups 1; ups 2]", err.Error())
+}
+
+func TestRejectedNewPromiseIsNotTimeout(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} {
+		return NewPromise(func(d interface{}) interface{} { return fmt.Errorf(testErr1) })
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr1)
+}
@@ -0,0 +1,158 @@
+package go_promise
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+	Executor runs handlers of promises
+
+	Execute mustn't block caller for a long time, because it's called
+	from NewPromise and from settlement of parent promise.
+ */
+type Executor interface {
+	Execute(task func())
+}
+
+var (
+	// every task in new goroutine, default executor
+	GoExecutor Executor = goExecutor{}
+	// every task in goroutine of caller, useful for tests
+	InlineExecutor Executor = inlineExecutor{}
+)
+
+type goExecutor struct{}
+
+func (goExecutor) Execute(task func()) {
+	go task()
+}
+
+type inlineExecutor struct{}
+
+func (inlineExecutor) Execute(task func()) {
+	task()
+}
+
+/*
+	Fixed count of workers with unbounded queue of tasks
+
+	Handler waiting other promise of the same pool by Get or Await
+	occupies worker, return promise instead.
+ */
+type PoolExecutor struct {
+	mutex  sync.Mutex
+	cond   *sync.Cond
+	tasks  []func()
+	closed bool
+	wg     sync.WaitGroup
+}
+
+/*
+	Create pool and start workers
+ */
+func NewPoolExecutor(workers int) *PoolExecutor {
+
+	if workers <= 0 {
+		workers = 1
+	}
+	e := &PoolExecutor{}
+	e.cond = sync.NewCond(&e.mutex)
+	e.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go e.work()
+	}
+	return e
+}
+
+/*
+	Add task to queue, closed pool runs task in new goroutine
+ */
+func (e *PoolExecutor) Execute(task func()) {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.closed {
+		go task()
+		return
+	}
+	e.tasks = append(e.tasks, task)
+	e.cond.Signal()
+}
+
+/*
+	Stop workers after execution of queued tasks
+ */
+func (e *PoolExecutor) Close() {
+
+	e.mutex.Lock()
+	e.closed = true
+	e.cond.Broadcast()
+	e.mutex.Unlock()
+	e.wg.Wait()
+}
+
+func (e *PoolExecutor) work() {
+
+	defer e.wg.Done()
+	for {
+		e.mutex.Lock()
+		for len(e.tasks) == 0 && !e.closed {
+			e.cond.Wait()
+		}
+		if len(e.tasks) == 0 {
+			e.mutex.Unlock()
+			return
+		}
+		task := e.tasks[0]
+		e.tasks[0] = nil
+		e.tasks = e.tasks[1:]
+		e.mutex.Unlock()
+
+		task()
+	}
+}
+
+type executorKey struct{}
+
+var executor = struct {
+	sync.RWMutex
+	value Executor
+}{value: GoExecutor}
+
+/*
+	Set package-wide executor, it's used by chains without own executor
+
+	nil sets GoExecutor.
+ */
+func SetExecutor(e Executor) {
+
+	if e == nil {
+		e = GoExecutor
+	}
+	executor.Lock()
+	defer executor.Unlock()
+	executor.value = e
+}
+
+/*
+	Executor for chain created by NewPromiseWithContext
+
+	Use:
+		ctx := WithExecutor(context.Background(), pool)
+		NewPromiseWithContext(ctx, ...).Then(...)
+ */
+func WithExecutor(ctx context.Context, e Executor) context.Context {
+
+	return context.WithValue(ctx, executorKey{}, e)
+}
+
+func executorFrom(ctx context.Context) Executor {
+
+	if e, ok := ctx.Value(executorKey{}).(Executor); ok && e != nil {
+		return e
+	}
+	executor.RLock()
+	defer executor.RUnlock()
+	return executor.value
+}
@@ -0,0 +1,121 @@
+package go_promise
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countExecutor struct {
+	count int32
+}
+
+func (e *countExecutor) Execute(task func()) {
+	atomic.AddInt32(&e.count, 1)
+	go task()
+}
+
+func TestInlineExecutor(t *testing.T) {
+
+	ctx := WithExecutor(context.Background(), InlineExecutor)
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 }).
+		Then(func(d interface{}) interface{} { return d.(string) + testStr2 })
+
+	// handlers are executed before return
+	assert.Equal(t, Success, promise.State())
+	assert.Equal(t, testStr1+testStr2, promise.Value())
+}
+
+func TestPoolExecutor(t *testing.T) {
+
+	pool := NewPoolExecutor(2)
+	defer pool.Close()
+	ctx := WithExecutor(context.Background(), pool)
+
+	var running, maxRunning int32
+	promises := make([]*Promise, 10)
+	for i := range promises {
+		promises[i] = NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return testStr1
+		})
+	}
+
+	for _, promise := range promises {
+		value, err := promise.Await()
+		assert.Equal(t, testStr1, value)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(2), maxRunning)
+}
+
+func TestPoolExecutorClose(t *testing.T) {
+
+	pool := NewPoolExecutor(1)
+	var done int32
+	for i := 0; i < 5; i++ {
+		pool.Execute(func() { atomic.AddInt32(&done, 1) })
+	}
+	pool.Close()
+	assert.Equal(t, int32(5), atomic.LoadInt32(&done))
+
+	// closed pool doesn't lose tasks
+	value, err := NewPromiseWithContext(WithExecutor(context.Background(), pool),
+		func(ctx context.Context, d interface{}) interface{} { return testStr1 }).Get()
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestChildInheritsExecutor(t *testing.T) {
+
+	executor := &countExecutor{}
+	ctx := WithExecutor(context.Background(), executor)
+
+	value, err := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 }).
+		Then(func(d interface{}) interface{} { return testStr2 }).
+		Then(func(d interface{}) interface{} { return testStr3 }).
+		Get()
+
+	assert.Equal(t, testStr3, value)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&executor.count))
+}
+
+func TestSetExecutor(t *testing.T) {
+
+	executor := &countExecutor{}
+	SetExecutor(executor)
+	defer SetExecutor(nil)
+
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+	// other tests can use executor too
+	assert.True(t, atomic.LoadInt32(&executor.count) >= 1)
+}
+
+func TestMapUsesExecutor(t *testing.T) {
+
+	executor := &countExecutor{}
+	SetExecutor(executor)
+	defer SetExecutor(nil)
+
+	value, err := Map([]interface{}{1, 2, 3}, 1, func(item interface{}) interface{} { return item }).Get()
+
+	assert.Equal(t, []interface{}{1, 2, 3}, value)
+	assert.NoError(t, err)
+	// handler and reaction of every item
+	assert.True(t, atomic.LoadInt32(&executor.count) >= 6)
+}
@@ -1,6 +1,7 @@
 package go_promise
 
 import (
+	"context"
 	"testing"
 	"github.com/stretchr/testify/assert"
 	"fmt"
@@ -73,20 +74,26 @@ func TestReject(t *testing.T) {
 
 func TestRaceByRaceCondition(t *testing.T) {
 
-	value, err := Race(
+	clock := NewFakeClock(time.Now())
+	promise := Race(
 		func(d interface{}) interface{} {
-			time.Sleep(600 * time.Millisecond)
+			<-clock.After(600 * time.Millisecond)
 			return testStr1
 		},
 		func(d interface{}) interface{} {
-			time.Sleep(200 * time.Millisecond)
+			<-clock.After(200 * time.Millisecond)
 			return testStr2
 		},
 		func(d interface{}) interface{} {
-			time.Sleep(800 * time.Millisecond)
+			<-clock.After(800 * time.Millisecond)
 			return testStr3
 		},
-	).GetWithTimeout(2 * time.Second)
+	)
+
+	clock.BlockUntil(3)
+	clock.Advance(200 * time.Millisecond)
+	value, err := promise.GetWithTimeout(2 * time.Second)
+	clock.Advance(600 * time.Millisecond)
 
 	assert.Equal(t, testStr2, value)
 	assert.NoError(t, err)
@@ -94,65 +101,151 @@ func TestRaceByRaceCondition(t *testing.T) {
 
 func TestRaceByError(t *testing.T) {
 
-	value, err := Race(
+	clock := NewFakeClock(time.Now())
+	promise := Race(
 		func(d interface{}) interface{} {
-			time.Sleep(600 * time.Millisecond)
+			<-clock.After(600 * time.Millisecond)
 			return testStr1
 		},
 		func(d interface{}) interface{} {
-			time.Sleep(200 * time.Millisecond)
+			<-clock.After(200 * time.Millisecond)
 			return fmt.Errorf(testErr1)
 		},
 		func(d interface{}) interface{} {
-			time.Sleep(800 * time.Millisecond)
+			<-clock.After(800 * time.Millisecond)
 			return testStr3
 		},
-	).GetWithTimeout(2 * time.Second)
+	)
 
-	assert.Equal(t, testStr1, value)
-	assert.NoError(t, err)
+	clock.BlockUntil(3)
+	clock.Advance(200 * time.Millisecond)
+	value, err := promise.GetWithTimeout(2 * time.Second)
+	clock.Advance(600 * time.Millisecond)
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr1)
 }
 
 func TestRaceByAllError(t *testing.T) {
 
-	value, err := Race(
+	clock := NewFakeClock(time.Now())
+	promise := Race(
 		func(d interface{}) interface{} {
-			time.Sleep(600 * time.Millisecond)
+			<-clock.After(600 * time.Millisecond)
 			return fmt.Errorf(testErr1)
 		},
 		func(d interface{}) interface{} {
-			time.Sleep(200 * time.Millisecond)
+			<-clock.After(200 * time.Millisecond)
 			return fmt.Errorf(testErr2)
 		},
 		func(d interface{}) interface{} {
-			time.Sleep(800 * time.Millisecond)
+			<-clock.After(800 * time.Millisecond)
 			return fmt.Errorf(testErr3)
 		},
-	).GetWithTimeout(2 * time.Second)
+	)
+
+	clock.BlockUntil(3)
+	clock.Advance(200 * time.Millisecond)
+	value, err := promise.GetWithTimeout(2 * time.Second)
+	clock.Advance(600 * time.Millisecond)
 
 	assert.Equal(t, nil, value)
-	assert.Errorf(t, err, "not success promises")
+	assert.EqualError(t, err, testErr2)
 }
 
-func TestRaceByTimeout(t *testing.T) {
+func TestAnyByError(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	promise := Any(
+		func(d interface{}) interface{} {
+			<-clock.After(600 * time.Millisecond)
+			return testStr1
+		},
+		func(d interface{}) interface{} {
+			<-clock.After(200 * time.Millisecond)
+			return fmt.Errorf(testErr1)
+		},
+		func(d interface{}) interface{} {
+			<-clock.After(800 * time.Millisecond)
+			return testStr3
+		},
+	)
+
+	clock.BlockUntil(3)
+	clock.Advance(200 * time.Millisecond)
+	clock.Advance(400 * time.Millisecond)
+	value, err := promise.GetWithTimeout(2 * time.Second)
+	clock.Advance(200 * time.Millisecond)
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestAnyByAllError(t *testing.T) {
 
-	value, err := Race(
+	clock := NewFakeClock(time.Now())
+	promise := Any(
 		func(d interface{}) interface{} {
-			time.Sleep(500 * time.Millisecond)
+			<-clock.After(600 * time.Millisecond)
 			return fmt.Errorf(testErr1)
 		},
 		func(d interface{}) interface{} {
-			time.Sleep(400 * time.Millisecond)
+			<-clock.After(200 * time.Millisecond)
 			return fmt.Errorf(testErr2)
 		},
 		func(d interface{}) interface{} {
-			time.Sleep(800 * time.Millisecond)
+			<-clock.After(800 * time.Millisecond)
 			return fmt.Errorf(testErr3)
 		},
-	).Get()
+	)
+
+	clock.BlockUntil(3)
+	clock.Advance(800 * time.Millisecond)
+	value, err := promise.GetWithTimeout(2 * time.Second)
 
 	assert.Equal(t, nil, value)
-	assert.Errorf(t, err, "timeout error")
+	assert.ErrorIs(t, err, ErrNoSuccess)
+	var aggregateErr *AggregateError
+	assert.ErrorAs(t, err, &aggregateErr)
+	// input order, not settlement order
+	assert.EqualError(t, aggregateErr.Errors[0], testErr1)
+	assert.EqualError(t, aggregateErr.Errors[1], testErr2)
+	assert.EqualError(t, aggregateErr.Errors[2], testErr3)
+}
+
+func TestRaceByTimeout(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	promise := Race(
+		func(d interface{}) interface{} {
+			<-clock.After(500 * time.Millisecond)
+			return fmt.Errorf(testErr1)
+		},
+		func(d interface{}) interface{} {
+			<-clock.After(400 * time.Millisecond)
+			return fmt.Errorf(testErr2)
+		},
+		func(d interface{}) interface{} {
+			<-clock.After(800 * time.Millisecond)
+			return fmt.Errorf(testErr3)
+		},
+	)
+
+	result := make(chan error)
+	go func() {
+		value, err := promise.Get()
+		assert.Equal(t, nil, value)
+		result <- err
+	}()
+
+	// 3 handlers and Get
+	clock.BlockUntil(4)
+	clock.Advance(defaultTimeout)
+	assert.ErrorIs(t, <-result, ErrTimeout)
+	clock.Advance(800 * time.Millisecond)
 }
 
 func TestResolveNormFunc(t *testing.T) {
@@ -202,3 +295,167 @@ func TestResolveValue(t *testing.T) {
 	assert.Equal(t, testStr1, value)
 	assert.NoError(t, err)
 }
+
+func TestNewPromiseWithContext(t *testing.T) {
+
+	value, err := NewPromiseWithContext(context.Background(), func(ctx context.Context, d interface{}) interface{} {
+		return testStr1
+	}).Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestNewPromiseWithContextByCancel(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan bool)
+	childStarted := false
+
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		close(started)
+		<-ctx.Done()
+		return testStr1
+	})
+	child := promise.Then(func(d interface{}) interface{} {
+		childStarted = true
+		return testStr2
+	})
+
+	<-started
+	cancel()
+
+	value, err := child.Get()
+	assert.Equal(t, nil, value)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	value, err = promise.Get()
+	assert.Equal(t, nil, value)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, childStarted)
+}
+
+func TestNewPromiseWithContextByDeadline(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	value, err := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		time.Sleep(100 * time.Millisecond)
+		return testStr1
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewPromiseWithCancelledContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	started := false
+
+	value, err := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		started = true
+		return testStr1
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, started)
+}
+
+func TestAllSettled(t *testing.T) {
+
+	parent := NewPromise(func(d interface{}) interface{} { return testStr3 })
+	value, err := AllSettled(
+		func(d interface{}) interface{} { return testStr1 },
+		func(d interface{}) interface{} { return fmt.Errorf(testErr2) },
+		parent,
+		func() { panic(testStr4) },
+	).Get()
+
+	assert.NoError(t, err)
+	outcomes := value.([]Outcome)
+	assert.Len(t, outcomes, 4)
+
+	assert.Equal(t, Success, outcomes[0].Status)
+	assert.Equal(t, testStr1, outcomes[0].Value)
+	assert.NoError(t, outcomes[0].Err)
+
+	assert.Equal(t, Rejected, outcomes[1].Status)
+	assert.Equal(t, nil, outcomes[1].Value)
+	assert.EqualError(t, outcomes[1].Err, testErr2)
+
+	assert.Equal(t, Success, outcomes[2].Status)
+	assert.Equal(t, testStr3, outcomes[2].Value)
+	assert.Equal(t, parent.id, outcomes[2].Id)
+
+	assert.Equal(t, Rejected, outcomes[3].Status)
+	assert.IsType(t, &PanicError{}, outcomes[3].Err)
+}
+
+func TestAllSettledInInputOrder(t *testing.T) {
+
+	value, err := AllSettled(
+		func(d interface{}) interface{} {
+			time.Sleep(100 * time.Millisecond)
+			return testStr1
+		},
+		func(d interface{}) interface{} { return testStr2 },
+	).Get()
+
+	assert.NoError(t, err)
+	outcomes := value.([]Outcome)
+	assert.Equal(t, testStr1, outcomes[0].Value)
+	assert.Equal(t, testStr2, outcomes[1].Value)
+}
+
+func TestAllSettledWithoutPromises(t *testing.T) {
+
+	value, err := AllSettled().Get()
+
+	assert.Equal(t, []Outcome{}, value)
+	assert.NoError(t, err)
+}
+
+func TestRaceWithPromises(t *testing.T) {
+
+	slow := NewPromise(func(d interface{}) interface{} {
+		time.Sleep(200 * time.Millisecond)
+		return testStr1
+	})
+	fast := Resolve(testStr2)
+
+	value, err := Race(slow, fast).Get()
+
+	assert.Equal(t, testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestRaceWithoutPromises(t *testing.T) {
+
+	promise := Race()
+	_, err := promise.GetWithTimeout(50 * time.Millisecond)
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, Pending, promise.State())
+}
+
+func TestAnyWithPromises(t *testing.T) {
+
+	value, err := Any(Reject(fmt.Errorf(testErr1)), Resolve(testStr2)).Get()
+
+	assert.Equal(t, testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestAnyWithoutPromises(t *testing.T) {
+
+	value, err := Any().Get()
+
+	assert.Equal(t, nil, value)
+	assert.ErrorIs(t, err, ErrNoSuccess)
+}
@@ -1,8 +1,8 @@
 package go_promise
 
 import (
-	"fmt"
-	"log"
+	"context"
+	"sync"
 )
 
 /*
@@ -12,92 +12,249 @@ func NewPromise(onSuccess func(value interface{}) interface{}) *Promise {
 
 	promise := newPromise(nil)
 	promise.onSuccess = onSuccess
-	go promise.process(nil)
+	promise.start(nil)
+	return promise
+}
+
+/*
+	Create parent promise with context
+
+	Context is inherited by all child promises. When ctx is cancelled
+	all pending promises of chain are rejected with CancelledError
+	and not started handlers will be never called.
+ */
+func NewPromiseWithContext(ctx context.Context,
+	onSuccess func(ctx context.Context, value interface{}) interface{}) *Promise {
+
+	promise := newContextPromise(ctx, nil)
+	promise.onSuccess = withContext(promise, onSuccess)
+	promise.start(nil)
 	return promise
 }
 
 /*
 	Wait execute all process or error
+
+	Result is []interface{} in input order, first error rejects promise.
+	JS example: Promise.all([...]);
  */
 func All(functions ...func(value interface{}) interface{}) *Promise {
 
 	childs := make([]*Promise, len(functions), len(functions))
+	for i, onSuccess := range functions {
+		childs[i] = NewPromise(onSuccess)
+	}
 
-	promiseFun := func(d interface{}) interface{} {
-		result := make([]interface{}, len(functions), len(functions))
-		for i, onSuccess := range functions {
-			childs[i] = NewPromise(onSuccess)
+	deferred := NewDeferred()
+	values := make([]interface{}, len(childs), len(childs))
+	if len(childs) == 0 {
+		deferred.Resolve(values)
+	}
+	var mutex sync.Mutex
+	remaining := len(childs)
+	whenSettled(childs, func(i int) {
+		child := childs[i]
+		if child.State() == Rejected {
+			deferred.Reject(child.Err())
+			return
 		}
-		for i, child := range childs {
-			value, err := child.Get()
-			if err != nil {
-				return err
-			}
-			result[i] = value
+		mutex.Lock()
+		values[i] = child.Value()
+		remaining--
+		done := remaining == 0
+		mutex.Unlock()
+		if done {
+			deferred.Resolve(values)
 		}
-		return result
-	}
+	})
+	return deferred.Promise
+}
 
-	return NewPromise(promiseFun)
+/*
+	Outcome of settled promise for AllSettled
+
+	Id - id of promise
+	Status - Success or Rejected
+	Value - value of fulfilled promise
+	Err - error of rejected promise
+ */
+type Outcome struct {
+	Id     string
+	Status State
+	Value  interface{}
+	Err    error
 }
 
 /*
-	get first success result
+	Wait settlement of all promises, result is []Outcome in input order
+
+	Promise is never rejected, errors are in outcomes.
+	Use:
+		AllSettled(
+			func(d interface{}) interface{} { ... },
+			NewPromise(...),
+			...
+		)
+	JS example: Promise.allSettled([...]);
  */
-func Race(functions ...func(value interface{}) interface{}) *Promise {
+func AllSettled(promises ...interface{}) *Promise {
 
-	childs := make([]*Promise, len(functions), len(functions))
+	childs := toPromises(promises)
+	deferred := NewDeferred()
+	outcomes := make([]Outcome, len(childs), len(childs))
+	if len(childs) == 0 {
+		deferred.Resolve(outcomes)
+	}
+	var mutex sync.Mutex
+	remaining := len(childs)
+	whenSettled(childs, func(i int) {
+		child := childs[i]
+		mutex.Lock()
+		outcomes[i] = Outcome{
+			Id:     child.id,
+			Status: child.State(),
+			Value:  child.Value(),
+			Err:    child.Err(),
+		}
+		remaining--
+		done := remaining == 0
+		mutex.Unlock()
+		if done {
+			deferred.Resolve(outcomes)
+		}
+	})
+	return deferred.Promise
+}
 
-	promiseFun := func(d interface{}) interface{} {
+/*
+	Settle by first settled promise, value or error
 
-		result := make(chan *Promise)
+	Use:
+		Race(
+			func(d interface{}) interface{} { ... },
+			NewPromise(...),
+			...
+		)
+	Race without promises is never settled like JS.
+	JS example: Promise.race([...]);
+ */
+func Race(promises ...interface{}) *Promise {
 
-		for i, onSuccess := range functions {
-			childs[i] = NewPromise(onSuccess)
-		}
+	if len(promises) == 0 {
+		return newPromise(nil)
+	}
 
-		for _, child := range childs {
-			go func(p *Promise) {
-				select {
-				case _, ok := <-p.final:
-					if !ok {
-						result <- p
-					}
-				}
-			}(child)
+	childs := toPromises(promises)
+	deferred := NewDeferred()
+	whenSettled(childs, func(i int) {
+		p := childs[i]
+		p.log(LevelDebug, "settled in race")
+		if p.State() == Success {
+			deferred.Resolve(p.Value())
+			return
 		}
+		deferred.Reject(p.Err())
+	})
+	deferred.Promise.log(LevelDebug, "race is started")
+	return deferred.Promise
+}
 
-		for i := 1; i <= len(childs); i++ {
+/*
+	get first success result
 
-			p := <-result
-			log.Printf("%v is first (%v)", p, p.state)
-			if p.state == success {
-				return p.result.value
-			}
-		}
+	If all promises are rejected then promise is rejected by AggregateError
+	with errors in input order.
+	JS example: Promise.any([...]);
+ */
+func Any(promises ...interface{}) *Promise {
 
-		return fmt.Errorf("not success promises")
+	childs := toPromises(promises)
+	deferred := NewDeferred()
+	errs := make([]error, len(childs), len(childs))
+	if len(childs) == 0 {
+		deferred.Reject(&AggregateError{Id: deferred.Promise.id, Errors: errs})
 	}
+	var mutex sync.Mutex
+	remaining := len(childs)
+	whenSettled(childs, func(i int) {
+		p := childs[i]
+		p.log(LevelDebug, "settled in any")
+		if p.State() == Success {
+			deferred.Resolve(p.Value())
+			return
+		}
+		mutex.Lock()
+		errs[i] = p.Err()
+		remaining--
+		done := remaining == 0
+		mutex.Unlock()
+		if done {
+			deferred.Reject(&AggregateError{Id: deferred.Promise.id, Errors: errs})
+		}
+	})
+	deferred.Promise.log(LevelDebug, "any is started")
+	return deferred.Promise
+}
 
-	promise := NewPromise(promiseFun)
-	log.Printf("%v is Race promise", promise)
-	return promise
+/*
+	Call f with index of every promise after its settlement
+
+	f is a job of executor of promise, goroutines aren't blocked by waiting.
+ */
+func whenSettled(promises []*Promise, f func(i int)) {
+
+	for i, promise := range promises {
+		i := i
+		promise.react(func() { f(i) })
+	}
 }
 
 /*
 	resolve data like JS
+
+	Promise is settled without handler, promise or thenable is adopted.
  */
 func Resolve(d interface{}) *Promise {
 
-	return NewPromise(func(value interface{}) interface{} { return d })
+	promise := newPromise(nil)
+	promise.settleWith(d)
+	return promise
 }
 
 /*
-	resolve data like JS
+	reject like JS, nil error rejects promise by ErrNilReason
  */
 func Reject(err error) *Promise {
 
-	return Resolve(err)
+	if err == nil {
+		err = ErrNilReason
+	}
+	promise := newPromise(nil)
+	promise.finalize(Rejected, &result{
+		resultType: ERROR,
+		err:        err,
+	})
+	return promise
+}
+
+/*
+	Use promise as is, other data is resolved by F
+ */
+func toPromise(d interface{}) *Promise {
+
+	if promise, ok := d.(*Promise); ok {
+		return promise
+	}
+	return NewPromise(F(d))
+}
+
+func toPromises(data []interface{}) []*Promise {
+
+	promises := make([]*Promise, len(data), len(data))
+	for i, d := range data {
+		promises[i] = toPromise(d)
+	}
+	return promises
 }
 
 /*
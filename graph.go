@@ -0,0 +1,247 @@
+package go_promise
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+	Registry of promises and their relations, it's Hooks
+
+	It helps to find stuck promise of chain: Running node is pending promise
+	with started handler, pending node without running handler waits its parent.
+
+	Use:
+		registry := NewRegistry()
+		unregister := RegisterHooks(registry)
+		...
+		registry.WriteDOT(os.Stdout)
+ */
+type Registry struct {
+	mutex sync.Mutex
+	clock Clock
+	nodes map[string]*Node
+}
+
+/*
+	Promise in registry
+
+	Id - id of promise
+	ParentId - id of parent promise, empty for parent promise
+	Children - ids of promises added by Then, Catch and etc.
+	Label - label of promise or name of last handler
+	Running - handler is running
+	Err - rejection reason
+ */
+type Node struct {
+	Id       string    `json:"id"`
+	ParentId string    `json:"parent_id,omitempty"`
+	Children []string  `json:"children,omitempty"`
+	State    State     `json:"state"`
+	Label    string    `json:"label,omitempty"`
+	Running  bool      `json:"running"`
+	Err      string    `json:"error,omitempty"`
+	Created  time.Time `json:"created"`
+	Started  time.Time `json:"started"`
+	Settled  time.Time `json:"settled"`
+}
+
+func NewRegistry() *Registry {
+
+	return NewRegistryWithClock(RealClock)
+}
+
+func NewRegistryWithClock(clock Clock) *Registry {
+
+	return &Registry{clock: clock, nodes: make(map[string]*Node)}
+}
+
+func (r *Registry) OnCreate(id, parentId string) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.nodes[id] = &Node{Id: id, ParentId: parentId, State: Pending, Created: r.clock.Now()}
+}
+
+func (r *Registry) OnHandlerStart(id, label string) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if node, ok := r.nodes[id]; ok {
+		node.Label = label
+		node.Running = true
+		if node.Started.IsZero() {
+			node.Started = r.clock.Now()
+		}
+	}
+}
+
+func (r *Registry) OnHandlerEnd(id string, err error) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if node, ok := r.nodes[id]; ok {
+		node.Running = false
+	}
+}
+
+func (r *Registry) OnSettle(id string, state State, err error) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if node, ok := r.nodes[id]; ok {
+		node.State = state
+		node.Settled = r.clock.Now()
+		if err != nil {
+			node.Err = err.Error()
+		}
+	}
+}
+
+func (r *Registry) OnChildAttached(parentId, childId string) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if node, ok := r.nodes[parentId]; ok {
+		node.Children = append(node.Children, childId)
+	}
+}
+
+/*
+	Copy of node, false if promise isn't registered
+ */
+func (r *Registry) Node(id string) (Node, bool) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	node, ok := r.nodes[id]
+	if !ok {
+		return Node{}, false
+	}
+	return node.copy(), true
+}
+
+/*
+	Copy of all nodes sorted by id
+ */
+func (r *Registry) Nodes() []Node {
+
+	r.mutex.Lock()
+	nodes := make([]Node, 0, len(r.nodes))
+	for _, node := range r.nodes {
+		nodes = append(nodes, node.copy())
+	}
+	r.mutex.Unlock()
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+	return nodes
+}
+
+/*
+	Pending promises sorted by id
+ */
+func (r *Registry) Pending() []Node {
+
+	var pending []Node
+	for _, node := range r.Nodes() {
+		if node.State == Pending {
+			pending = append(pending, node)
+		}
+	}
+	return pending
+}
+
+/*
+	Remove settled promises, registry keeps all promises until Prune
+ */
+func (r *Registry) Prune() {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for id, node := range r.nodes {
+		if node.State != Pending {
+			delete(r.nodes, id)
+		}
+	}
+}
+
+/*
+	Write graph in Graphviz DOT format
+
+	Pending promise is yellow, running is bold, success is green, rejected is red.
+	Edge is dashed if child isn't in registry.
+ */
+func (r *Registry) WriteDOT(w io.Writer) error {
+
+	nodes := r.Nodes()
+	known := make(map[string]bool, len(nodes))
+	for _, node := range nodes {
+		known[node.Id] = true
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph promises {\n\tnode [shape=box, style=filled];\n")
+	for _, node := range nodes {
+		label := node.Id + "\\n" + node.State.String()
+		if node.Label != "" {
+			label = node.Id + "\\n" + dotEscape(node.Label) + "\\n" + node.State.String()
+		}
+		style := "filled"
+		if node.Running {
+			style = "filled,bold"
+		}
+		fmt.Fprintf(&b, "\t%q [label=\"%s\", fillcolor=%s, style=%q];\n",
+			node.Id, label, dotColor(node.State), style)
+	}
+	for _, node := range nodes {
+		for _, child := range node.Children {
+			if known[child] {
+				fmt.Fprintf(&b, "\t%q -> %q;\n", node.Id, child)
+			} else {
+				fmt.Fprintf(&b, "\t%q -> %q [style=dashed];\n", node.Id, child)
+			}
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+/*
+	Write nodes sorted by id as JSON array
+ */
+func (r *Registry) WriteJSON(w io.Writer) error {
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r.Nodes())
+}
+
+func (n *Node) copy() Node {
+
+	c := *n
+	c.Children = append([]string(nil), n.Children...)
+	return c
+}
+
+func dotColor(state State) string {
+
+	switch state {
+	case Success:
+		return "palegreen"
+	case Rejected:
+		return "lightcoral"
+	default:
+		return "khaki"
+	}
+}
+
+func dotEscape(s string) string {
+
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
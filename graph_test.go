@@ -0,0 +1,120 @@
+package go_promise
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryOfChain(t *testing.T) {
+
+	registry := NewRegistry()
+	ctx := WithHooks(context.Background(), registry)
+
+	parent := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 }).
+		Label("load")
+	child := parent.Then(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).Label("save")
+	child.Await()
+
+	node, ok := registry.Node(parent.id)
+	assert.True(t, ok)
+	assert.Equal(t, []string{child.id}, node.Children)
+	assert.Equal(t, Success, node.State)
+	assert.Equal(t, "load", node.Label)
+	assert.False(t, node.Settled.IsZero())
+
+	node, _ = registry.Node(child.id)
+	assert.Equal(t, parent.id, node.ParentId)
+	assert.Equal(t, Rejected, node.State)
+	assert.Equal(t, testErr1, node.Err)
+	assert.Len(t, registry.Nodes(), 2)
+
+	registry.Prune()
+	assert.Empty(t, registry.Nodes())
+}
+
+func TestRegistryStuckPromise(t *testing.T) {
+
+	registry := NewRegistry()
+	ctx := WithHooks(context.Background(), registry)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	parent := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		close(started)
+		<-release
+		return testStr1
+	}).Label("stuck")
+	child := parent.Then(func(d interface{}) interface{} { return d })
+	<-started
+
+	pending := registry.Pending()
+	assert.Len(t, pending, 2)
+	assert.Equal(t, parent.id, pending[0].Id)
+	assert.True(t, pending[0].Running)
+	assert.False(t, pending[1].Running)
+
+	var dot bytes.Buffer
+	assert.NoError(t, registry.WriteDOT(&dot))
+	assert.Contains(t, dot.String(), fmt.Sprintf("%q [label=\"%s\\nstuck\\npending\", fillcolor=khaki, style=\"filled,bold\"];", parent.id, parent.id))
+	assert.Contains(t, dot.String(), fmt.Sprintf("%q -> %q;", parent.id, child.id))
+
+	close(release)
+	child.Await()
+}
+
+func TestRegistryManyChains(t *testing.T) {
+
+	registry := NewRegistry()
+	ctx := WithHooks(context.Background(), registry)
+
+	parents := make([]*Promise, 10000)
+	children := make([]*Promise, len(parents))
+	for i := range parents {
+		parents[i] = NewDeferredWithContext(ctx).Promise
+		children[i] = parents[i].Then(nil)
+	}
+
+	assert.Len(t, registry.Nodes(), 2*len(parents))
+	for i, parent := range parents {
+		node, ok := registry.Node(parent.id)
+		assert.True(t, ok)
+		assert.Equal(t, []string{children[i].id}, node.Children)
+	}
+}
+
+func TestRegistryJSON(t *testing.T) {
+
+	registry := NewRegistryWithClock(NewFakeClock(time.Unix(0, 0)))
+	ctx := WithHooks(context.Background(), registry)
+
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 })
+	promise.Await()
+
+	var buffer bytes.Buffer
+	assert.NoError(t, registry.WriteJSON(&buffer))
+	var nodes []Node
+	assert.NoError(t, json.Unmarshal(buffer.Bytes(), &nodes))
+	assert.Len(t, nodes, 1)
+	assert.Equal(t, promise.id, nodes[0].Id)
+	assert.Equal(t, Success, nodes[0].State)
+	assert.Contains(t, buffer.String(), `"state": "success"`)
+}
+
+func TestRegistryDOTEscape(t *testing.T) {
+
+	registry := NewRegistry()
+	registry.OnCreate("1", "")
+	registry.OnHandlerStart("1", `say "hi"`)
+	registry.OnChildAttached("1", "1.1")
+
+	var dot bytes.Buffer
+	assert.NoError(t, registry.WriteDOT(&dot))
+	assert.Contains(t, dot.String(), `say \"hi\"`)
+	assert.Contains(t, dot.String(), `"1" -> "1.1" [style=dashed];`)
+}
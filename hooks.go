@@ -0,0 +1,232 @@
+package go_promise
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+/*
+	Observer of promise lifecycle, like async_hooks of Node.js
+
+	OnCreate - promise is created, parentId is empty for parent promise
+	OnHandlerStart - handler is started, label is set by Label or name of handler function
+	OnHandlerEnd - handler is finished, err is its error or panic
+	OnSettle - promise is settled, err is nil for Success
+	OnChildAttached - child promise is added by Then, Catch, Finally and etc.
+
+	Hooks are called synchronously in goroutine of promise, so they must be fast.
+	Embed NopHooks to implement only needed methods.
+ */
+type Hooks interface {
+	OnCreate(id, parentId string)
+	OnHandlerStart(id, label string)
+	OnHandlerEnd(id string, err error)
+	OnSettle(id string, state State, err error)
+	OnChildAttached(parentId, childId string)
+}
+
+/*
+	Optional interface of Hooks, OnWaitTimeout is called when GetWithTimeout returns TimeoutError
+ */
+type WaitTimeoutHooks interface {
+	OnWaitTimeout(id string, timeout time.Duration)
+}
+
+/*
+	Optional interface of Hooks, OnAdopt is called when promise waits promise returned by handler
+ */
+type AdoptHooks interface {
+	OnAdopt(id, adoptedId string)
+}
+
+/*
+	Hooks which do nothing
+ */
+type NopHooks struct{}
+
+func (NopHooks) OnCreate(id, parentId string) {
+}
+
+func (NopHooks) OnHandlerStart(id, label string) {
+}
+
+func (NopHooks) OnHandlerEnd(id string, err error) {
+}
+
+func (NopHooks) OnSettle(id string, state State, err error) {
+}
+
+func (NopHooks) OnChildAttached(parentId, childId string) {
+}
+
+type hooksKey struct{}
+
+type hooksEntry struct {
+	hooks Hooks
+}
+
+/*
+	entries - registered hooks
+	value - snapshot of entries for promises, it's never changed
+ */
+var hooks = struct {
+	sync.RWMutex
+	entries []*hooksEntry
+	value   []Hooks
+}{}
+
+/*
+	Register package-wide hooks for all new promises
+
+	Returned function unregisters hooks.
+ */
+func RegisterHooks(h Hooks) (unregister func()) {
+
+	entry := &hooksEntry{hooks: h}
+	hooks.Lock()
+	defer hooks.Unlock()
+	hooks.entries = append(hooks.entries, entry)
+	hooks.value = hooksSnapshot(hooks.entries)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			hooks.Lock()
+			defer hooks.Unlock()
+			for i, e := range hooks.entries {
+				if e == entry {
+					hooks.entries = append(hooks.entries[:i:i], hooks.entries[i+1:]...)
+					break
+				}
+			}
+			hooks.value = hooksSnapshot(hooks.entries)
+		})
+	}
+}
+
+/*
+	Hooks for chain created by NewPromiseWithContext, in addition to package-wide hooks
+ */
+func WithHooks(ctx context.Context, h Hooks) context.Context {
+
+	chain, _ := ctx.Value(hooksKey{}).([]Hooks)
+	value := make([]Hooks, len(chain), len(chain)+1)
+	copy(value, chain)
+	return context.WithValue(ctx, hooksKey{}, append(value, h))
+}
+
+func hooksSnapshot(entries []*hooksEntry) []Hooks {
+
+	value := make([]Hooks, len(entries), len(entries))
+	for i, entry := range entries {
+		value[i] = entry.hooks
+	}
+	return value
+}
+
+func hooksFrom(ctx context.Context) []Hooks {
+
+	hooks.RLock()
+	global := hooks.value
+	hooks.RUnlock()
+
+	chain, _ := ctx.Value(hooksKey{}).([]Hooks)
+	if len(chain) == 0 {
+		return global
+	}
+	if len(global) == 0 {
+		return chain
+	}
+	value := make([]Hooks, 0, len(global)+len(chain))
+	return append(append(value, global...), chain...)
+}
+
+/*
+	Set label of promise for hooks, tracing and etc.
+
+	Label must be set before start of handler.
+ */
+func (p *Promise) Label(label string) *Promise {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.label = label
+	return p
+}
+
+/*
+	Label of promise or name of handler function
+ */
+func (p *Promise) handlerLabel(handler interface{}) string {
+
+	p.mutex.Lock()
+	label := p.label
+	p.mutex.Unlock()
+	if label != "" {
+		return label
+	}
+	if f := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()); f != nil {
+		return f.Name()
+	}
+	return ""
+}
+
+func (p *Promise) hookCreate() {
+
+	for _, h := range p.hooks {
+		h.OnCreate(p.id, p.parentId)
+	}
+}
+
+func (p *Promise) hookHandlerStart(handler interface{}) {
+
+	if len(p.hooks) == 0 {
+		return
+	}
+	label := p.handlerLabel(handler)
+	for _, h := range p.hooks {
+		h.OnHandlerStart(p.id, label)
+	}
+}
+
+func (p *Promise) hookHandlerEnd(r *result) {
+
+	for _, h := range p.hooks {
+		h.OnHandlerEnd(p.id, r.err)
+	}
+}
+
+func (p *Promise) hookSettle(state State, err error) {
+
+	for _, h := range p.hooks {
+		h.OnSettle(p.id, state, err)
+	}
+}
+
+func (p *Promise) hookWaitTimeout(timeout time.Duration) {
+
+	for _, h := range p.hooks {
+		if w, ok := h.(WaitTimeoutHooks); ok {
+			w.OnWaitTimeout(p.id, timeout)
+		}
+	}
+}
+
+func (p *Promise) hookAdopt(adopted *Promise) {
+
+	for _, h := range p.hooks {
+		if a, ok := h.(AdoptHooks); ok {
+			a.OnAdopt(p.id, adopted.id)
+		}
+	}
+}
+
+func (p *Promise) hookChildAttached(child *Promise) {
+
+	for _, h := range p.hooks {
+		h.OnChildAttached(p.id, child.id)
+	}
+}
@@ -0,0 +1,155 @@
+package go_promise
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testHooks struct {
+	mutex  sync.Mutex
+	events map[string][]string
+}
+
+func newTestHooks() *testHooks {
+	return &testHooks{events: make(map[string][]string)}
+}
+
+func (h *testHooks) add(id, event string) {
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.events[id] = append(h.events[id], event)
+}
+
+func (h *testHooks) get(id string) []string {
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.events[id]
+}
+
+func (h *testHooks) OnCreate(id, parentId string) {
+	h.add(id, "create:"+parentId)
+}
+
+func (h *testHooks) OnHandlerStart(id, label string) {
+	h.add(id, "start:"+label)
+}
+
+func (h *testHooks) OnHandlerEnd(id string, err error) {
+	h.add(id, fmt.Sprintf("end:%v", err))
+}
+
+func (h *testHooks) OnSettle(id string, state State, err error) {
+	h.add(id, fmt.Sprintf("settle:%v:%v", state, err))
+}
+
+func (h *testHooks) OnChildAttached(parentId, childId string) {
+	h.add(parentId, "child:"+childId)
+}
+
+func TestHooksOfChain(t *testing.T) {
+
+	hooks := newTestHooks()
+	ctx := WithHooks(context.Background(), hooks)
+
+	parent := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 }).
+		Label("parent")
+	child := parent.Then(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).
+		Label("child")
+	_, err := child.Get()
+	assert.EqualError(t, err, testErr1)
+
+	parentEvents := hooks.get(parent.id)
+	assert.Equal(t, "create:", parentEvents[0])
+	assert.Contains(t, parentEvents, "child:"+child.id)
+	assert.Contains(t, parentEvents, "end:<nil>")
+	assert.Equal(t, "settle:success:<nil>", parentEvents[len(parentEvents)-1])
+
+	assert.Equal(t, []string{
+		"create:" + parent.id,
+		// error of handler doesn't go to catch handler of the same promise
+		"start:child",
+		"end:" + testErr1,
+		"settle:rejected:" + testErr1,
+	}, hooks.get(child.id))
+}
+
+func TestHooksHandlerPanic(t *testing.T) {
+
+	hooks := newTestHooks()
+	ctx := WithHooks(context.Background(), hooks)
+
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { panic(testStr1) })
+	_, err := promise.Get()
+	assert.Error(t, err)
+
+	events := hooks.get(promise.id)
+	assert.True(t, strings.HasPrefix(events[2], "end:promise "+promise.id+": panic: "+testStr1))
+}
+
+func TestRegisterHooks(t *testing.T) {
+
+	hooks := newTestHooks()
+	unregister := RegisterHooks(hooks)
+
+	registered := Resolve(testStr1)
+	<-registered.Done()
+	unregister()
+	unregister()
+	unregistered := Resolve(testStr1)
+	<-unregistered.Done()
+
+	assert.NotEmpty(t, hooks.get(registered.id))
+	assert.Empty(t, hooks.get(unregistered.id))
+}
+
+func TestHooksOfChainAndPackage(t *testing.T) {
+
+	global := newTestHooks()
+	chain := newTestHooks()
+	defer RegisterHooks(global)()
+
+	promise := NewPromiseWithContext(WithHooks(context.Background(), chain),
+		func(ctx context.Context, d interface{}) interface{} { return testStr1 })
+	<-promise.Done()
+
+	assert.Equal(t, global.get(promise.id), chain.get(promise.id))
+	assert.Len(t, chain.get(promise.id), 4)
+}
+
+type settleHooks struct {
+	NopHooks
+	settled chan State
+}
+
+func (h settleHooks) OnSettle(id string, state State, err error) {
+	h.settled <- state
+}
+
+func TestNopHooks(t *testing.T) {
+
+	hooks := settleHooks{settled: make(chan State, 1)}
+	ctx := WithHooks(context.Background(), hooks)
+
+	NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 })
+	assert.Equal(t, Success, <-hooks.settled)
+}
+
+func TestHooksHandlerName(t *testing.T) {
+
+	hooks := newTestHooks()
+	ctx := WithHooks(context.Background(), hooks)
+
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		return fmt.Errorf(testErr1)
+	}).Then(F(testStr1))
+	<-promise.Done()
+
+	assert.Equal(t, "start:github.com/danevge/go-promise.defaultOnRejected", hooks.get(promise.id)[1])
+}
@@ -0,0 +1,221 @@
+package go_promise
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+	Detector of promises which are pending too long, it's Hooks
+
+	It keeps creation stack of every pending promise, settled promises are removed.
+	Register it before creation of checked promises.
+
+	Use in tests:
+		detector := NewLeakDetector()
+		defer RegisterHooks(detector)()
+		...
+		detector.AssertNoLeaks(t, time.Second)
+ */
+type LeakDetector struct {
+	mutex    sync.Mutex
+	clock    Clock
+	promises map[string]*leakEntry
+}
+
+/*
+	Pending promise of detector
+
+	Id - id of promise
+	ParentId - id of parent promise, empty for parent promise
+	Label - label of promise or name of last handler
+	Running - handler is running
+	WaitingFor - id of promise which result is awaited: promise returned
+		by handler or parent for not started handler, empty for running
+		handler and promise settled outside (Deferred, Delay and etc.)
+	Age - time from creation
+	Stack - stack of creation
+ */
+type Leak struct {
+	Id         string
+	ParentId   string
+	Label      string
+	Running    bool
+	WaitingFor string
+	Age        time.Duration
+	Stack      string
+}
+
+/*
+	started - handler has been started
+	adopted - id of promise returned by handler
+	stack - program counters of creation
+ */
+type leakEntry struct {
+	parentId string
+	label    string
+	running  bool
+	started  bool
+	adopted  string
+	created  time.Time
+	stack    []uintptr
+}
+
+/*
+	TestingT is part of testing.TB which is used by AssertNoLeaks
+ */
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+const leakStackDepth = 32
+
+func NewLeakDetector() *LeakDetector {
+
+	return NewLeakDetectorWithClock(RealClock)
+}
+
+func NewLeakDetectorWithClock(clock Clock) *LeakDetector {
+
+	return &LeakDetector{clock: clock, promises: make(map[string]*leakEntry)}
+}
+
+func (d *LeakDetector) OnCreate(id, parentId string) {
+
+	// skip runtime.Callers, OnCreate, hookCreate and newContextPromise
+	pc := make([]uintptr, leakStackDepth)
+	pc = pc[:runtime.Callers(4, pc)]
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.promises[id] = &leakEntry{parentId: parentId, created: d.clock.Now(), stack: pc}
+}
+
+func (d *LeakDetector) OnHandlerStart(id, label string) {
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if entry, ok := d.promises[id]; ok {
+		entry.label = label
+		entry.running = true
+		entry.started = true
+	}
+}
+
+func (d *LeakDetector) OnHandlerEnd(id string, err error) {
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if entry, ok := d.promises[id]; ok {
+		entry.running = false
+	}
+}
+
+func (d *LeakDetector) OnSettle(id string, state State, err error) {
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.promises, id)
+}
+
+func (d *LeakDetector) OnChildAttached(parentId, childId string) {
+}
+
+func (d *LeakDetector) OnAdopt(id, adoptedId string) {
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if entry, ok := d.promises[id]; ok {
+		entry.adopted = adoptedId
+	}
+}
+
+/*
+	Promises which are pending at least threshold, sorted by id
+ */
+func (d *LeakDetector) Leaks(threshold time.Duration) []Leak {
+
+	now := d.clock.Now()
+	d.mutex.Lock()
+	var leaks []Leak
+	for id, entry := range d.promises {
+		age := now.Sub(entry.created)
+		if age < threshold {
+			continue
+		}
+		leaks = append(leaks, Leak{
+			Id:         id,
+			ParentId:   entry.parentId,
+			Label:      entry.label,
+			Running:    entry.running,
+			WaitingFor: entry.waitingFor(),
+			Age:        age,
+			Stack:      formatStack(entry.stack),
+		})
+	}
+	d.mutex.Unlock()
+
+	sort.Slice(leaks, func(i, j int) bool { return leaks[i].Id < leaks[j].Id })
+	return leaks
+}
+
+/*
+	Report every leak by t.Errorf, returns false if leaks are found
+ */
+func (d *LeakDetector) AssertNoLeaks(t TestingT, threshold time.Duration) bool {
+
+	t.Helper()
+	leaks := d.Leaks(threshold)
+	for _, leak := range leaks {
+		t.Errorf("%v", leak)
+	}
+	return len(leaks) == 0
+}
+
+func (l Leak) String() string {
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "promise %v is pending for %v", l.Id, l.Age)
+	switch {
+	case l.Running:
+		fmt.Fprintf(&b, ", handler %v is running", l.Label)
+	case l.WaitingFor != "":
+		fmt.Fprintf(&b, ", waiting for promise %v", l.WaitingFor)
+	}
+	fmt.Fprintf(&b, "\ncreated at:\n%s", l.Stack)
+	return b.String()
+}
+
+func (e *leakEntry) waitingFor() string {
+
+	switch {
+	case e.running:
+		return ""
+	case e.adopted != "":
+		return e.adopted
+	case !e.started:
+		return e.parentId
+	default:
+		return ""
+	}
+}
+
+func formatStack(pc []uintptr) string {
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(pc)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			return b.String()
+		}
+	}
+}
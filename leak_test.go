@@ -0,0 +1,132 @@
+package go_promise
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testingT struct {
+	errors []string
+}
+
+func (t *testingT) Helper() {
+}
+
+func (t *testingT) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestLeakDetector(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	detector := NewLeakDetectorWithClock(clock)
+	loop := NewEventLoop()
+	ctx := WithExecutor(WithHooks(context.Background(), detector), loop)
+
+	deferred := NewDeferredWithContext(ctx)
+	child := deferred.Promise.Then(func(d interface{}) interface{} { return d })
+	awaited := NewDeferredWithContext(ctx)
+	adopting := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		return awaited.Promise
+	})
+	NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 })
+	loop.RunUntilIdle()
+
+	clock.Advance(2 * time.Second)
+	NewDeferredWithContext(ctx)
+	leaks := detector.Leaks(time.Second)
+
+	waitingFor := make(map[string]string)
+	for _, leak := range leaks {
+		waitingFor[leak.Id] = leak.WaitingFor
+		assert.Equal(t, 2*time.Second, leak.Age)
+		assert.Contains(t, leak.Stack, "TestLeakDetector")
+	}
+	assert.Equal(t, map[string]string{
+		deferred.Promise.Id(): "",
+		child.Id():            deferred.Promise.Id(),
+		awaited.Promise.Id():  "",
+		adopting.Id():         awaited.Promise.Id(),
+	}, waitingFor)
+}
+
+func TestLeakDetectorAssert(t *testing.T) {
+
+	detector := NewLeakDetector()
+	deferred := NewDeferredWithContext(WithHooks(context.Background(), detector))
+
+	fake := &testingT{}
+	assert.False(t, detector.AssertNoLeaks(fake, 0))
+	assert.Len(t, fake.errors, 1)
+	assert.Contains(t, fake.errors[0], "promise "+deferred.Promise.Id()+" is pending")
+	assert.Contains(t, fake.errors[0], "created at:")
+
+	deferred.Resolve(testStr1)
+	fake = &testingT{}
+	assert.True(t, detector.AssertNoLeaks(fake, 0))
+	assert.Empty(t, fake.errors)
+}
+
+func TestLeakDetectorManyPromises(t *testing.T) {
+
+	detector := NewLeakDetector()
+	ctx := WithHooks(context.Background(), detector)
+
+	stuck := make([]*Deferred, 10000)
+	for i := range stuck {
+		stuck[i] = NewDeferredWithContext(ctx)
+		NewDeferredWithContext(ctx).Resolve(testStr1)
+	}
+
+	assert.Len(t, detector.Leaks(0), len(stuck))
+	for _, deferred := range stuck {
+		deferred.Resolve(testStr1)
+	}
+	assert.Empty(t, detector.Leaks(0))
+}
+
+func TestRaceDoesNotLeakGoroutines(t *testing.T) {
+
+	before := runtime.NumGoroutine()
+	promises := make([]interface{}, 0, 101)
+	pending := NewDeferred()
+	for i := 0; i < 100; i++ {
+		promises = append(promises, pending.Promise.Then(func(d interface{}) interface{} { return d }))
+	}
+	promises = append(promises, Resolve(testStr1))
+
+	value, err := Race(promises...).Await()
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+	// losers and pending children don't park goroutines
+	assert.Less(t, runtime.NumGoroutine()-before, 20)
+}
+
+func TestTimeoutDoesNotLeakGoroutines(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	pending := NewDeferredWithContext(WithClock(context.Background(), clock))
+	before := runtime.NumGoroutine()
+	for i := 0; i < 100; i++ {
+		pending.Promise.Timeout(time.Hour)
+	}
+	// timers of clock watch promises instead of goroutines
+	assert.Less(t, runtime.NumGoroutine()-before, 20)
+	pending.Resolve(testStr1)
+}
+
+func TestAdoptionLongerThanDefaultTimeout(t *testing.T) {
+
+	deferred := NewDeferred()
+	promise := NewPromise(func(d interface{}) interface{} { return deferred.Promise })
+	time.AfterFunc(2*defaultTimeout, func() { deferred.Resolve(testStr1) })
+
+	value, err := promise.Await()
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,180 @@
+package go_promise
+
+import (
+	"context"
+	"sync"
+)
+
+/*
+	Like All, but at most n functions are executed concurrently
+
+	Result is array in input order. First error rejects promise
+	and functions which aren't started are never called.
+	Functions aren't started after Cancel or Timeout too.
+	If n <= 0 then count of functions isn't limited.
+ */
+func AllLimit(n int, functions ...func(value interface{}) interface{}) *Promise {
+
+	return limit(context.Background(), n, false, len(functions), func(ctx context.Context, i int) interface{} {
+		return functions[i](nil)
+	})
+}
+
+/*
+	Like AllLimit, but with context of chain
+
+	Functions get context of chain and aren't started after its cancellation,
+	cancellation rejects promise by CancelledError.
+ */
+func AllLimitWithContext(ctx context.Context, n int,
+	functions ...func(ctx context.Context, value interface{}) interface{}) *Promise {
+
+	return limit(ctx, n, false, len(functions), func(ctx context.Context, i int) interface{} {
+		return functions[i](ctx, nil)
+	})
+}
+
+/*
+	Like AllLimit, but all functions are executed
+
+	If one of them returns error then promise is rejected by BatchError
+	with errors of all functions.
+ */
+func AllLimitCollect(n int, functions ...func(value interface{}) interface{}) *Promise {
+
+	return limit(context.Background(), n, true, len(functions), func(ctx context.Context, i int) interface{} {
+		return functions[i](nil)
+	})
+}
+
+/*
+	Call function for every item, at most n calls are executed concurrently
+
+	Function can return value, error or promise like handler of Then.
+	Result is array in input order. First error rejects promise
+	and items which aren't started are never processed.
+	Items aren't processed after Cancel or Timeout too.
+	If n <= 0 then count of calls isn't limited.
+
+	Use:
+		Map(ids, 10, func(id interface{}) interface{} { return load(id.(int)) })
+ */
+func Map(items []interface{}, n int, function func(item interface{}) interface{}) *Promise {
+
+	return limit(context.Background(), n, false, len(items), func(ctx context.Context, i int) interface{} {
+		return function(items[i])
+	})
+}
+
+/*
+	Like Map, but with context of chain
+
+	Function gets context of chain, items aren't processed after its cancellation
+	and cancellation rejects promise by CancelledError.
+
+	Use:
+		MapWithContext(r.Context(), ids, 10, func(ctx context.Context, id interface{}) interface{} {
+			return load(ctx, id.(int))
+		})
+ */
+func MapWithContext(ctx context.Context, items []interface{}, n int,
+	function func(ctx context.Context, item interface{}) interface{}) *Promise {
+
+	return limit(ctx, n, false, len(items), func(ctx context.Context, i int) interface{} {
+		return function(ctx, items[i])
+	})
+}
+
+/*
+	Like Map, but all items are processed
+
+	If one of calls returns error then promise is rejected by BatchError
+	with errors of all items.
+ */
+func MapCollect(items []interface{}, n int, function func(item interface{}) interface{}) *Promise {
+
+	return limit(context.Background(), n, true, len(items), func(ctx context.Context, i int) interface{} {
+		return function(items[i])
+	})
+}
+
+/*
+	Execute tasks by index with limit of concurrency
+
+	Next task is started by reaction to settlement of previous one,
+	so goroutines aren't blocked by waiting like in All.
+	ctx - context of promise and tasks
+	collect - wait all tasks instead of first error
+ */
+func limit(ctx context.Context, n int, collect bool, count int, task func(ctx context.Context, i int) interface{}) *Promise {
+
+	if n <= 0 || n > count {
+		n = count
+	}
+
+	deferred := NewDeferredWithContext(ctx)
+	promise := deferred.Promise
+	values := make([]interface{}, count, count)
+	errs := make([]error, count, count)
+	if count == 0 {
+		deferred.Resolve(values)
+		return promise
+	}
+
+	var mutex sync.Mutex
+	next := 0
+	remaining := count
+	failed := false
+
+	var run func()
+	run = func() {
+		mutex.Lock()
+		if next == count {
+			mutex.Unlock()
+			return
+		}
+		i := next
+		next++
+		mutex.Unlock()
+
+		// promise is cancelled, timed out or rejected, the rest of tasks isn't started
+		if err := promise.stopCause(); err != nil {
+			deferred.Reject(err)
+			return
+		}
+		child := newContextPromise(ctx, nil)
+		child.onSuccess = withContext(child, func(ctx context.Context, d interface{}) interface{} { return task(ctx, i) })
+		child.start(nil)
+		child.react(func() {
+			value, err := child.result.value, child.result.err
+			if err != nil && promise.logEnabled(LevelDebug) {
+				promise.log(LevelDebug, "task is failed", Field{Key: "task", Value: i}, Field{Key: "error", Value: err})
+			}
+			if err != nil && !collect {
+				deferred.Reject(err)
+				return
+			}
+
+			mutex.Lock()
+			values[i], errs[i] = value, err
+			failed = failed || err != nil
+			remaining--
+			done := remaining == 0
+			mutex.Unlock()
+
+			switch {
+			case !done:
+				run()
+			case failed:
+				deferred.Reject(&BatchError{Id: promise.id, Errors: errs})
+			default:
+				deferred.Resolve(values)
+			}
+		})
+	}
+
+	for i := 0; i < n; i++ {
+		run()
+	}
+	return promise
+}
@@ -0,0 +1,228 @@
+package go_promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAllLimit(t *testing.T) {
+
+	value, err := AllLimit(2,
+		func(d interface{}) interface{} { return testStr1 },
+		func(d interface{}) interface{} { return testStr2 },
+		func(d interface{}) interface{} { return testStr3 },
+		func(d interface{}) interface{} { return testStr4 },
+		func(d interface{}) interface{} { return testStr5 },
+	).Get()
+
+	testArray := []interface{}{testStr1, testStr2, testStr3, testStr4, testStr5}
+	assert.Equal(t, testArray, value)
+	assert.NoError(t, err)
+}
+
+func TestMapConcurrencyLimit(t *testing.T) {
+
+	items := make([]interface{}, 50)
+	for i := range items {
+		items[i] = i
+	}
+	var running, maxRunning int32
+
+	value, err := Map(items, 3, func(item interface{}) interface{} {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return item.(int) * 2
+	}).Await()
+
+	assert.NoError(t, err)
+	result := value.([]interface{})
+	for i := range items {
+		assert.Equal(t, i*2, result[i])
+	}
+	assert.Equal(t, int32(3), maxRunning)
+}
+
+func TestMapReturnPromise(t *testing.T) {
+
+	value, err := Map([]interface{}{testStr1, testStr2}, 1, func(item interface{}) interface{} {
+		return Resolve(item.(string) + testStr3)
+	}).Get()
+
+	assert.Equal(t, []interface{}{testStr1 + testStr3, testStr2 + testStr3}, value)
+	assert.NoError(t, err)
+}
+
+func TestMapFailFast(t *testing.T) {
+
+	items := []interface{}{1, 2, 3, 4, 5}
+	var called int32
+
+	value, err := Map(items, 1, func(item interface{}) interface{} {
+		atomic.AddInt32(&called, 1)
+		if item.(int) == 2 {
+			return fmt.Errorf(testErr1)
+		}
+		return item
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr1)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&called))
+}
+
+func TestMapStopsAfterCancel(t *testing.T) {
+
+	items := make([]interface{}, 100)
+	var called int32
+	started := make(chan bool)
+	release := make(chan bool)
+
+	promise := Map(items, 1, func(item interface{}) interface{} {
+		if atomic.AddInt32(&called, 1) == 1 {
+			started <- true
+			<-release
+		}
+		return item
+	})
+	<-started
+	promise.Cancel()
+	close(release)
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&called))
+}
+
+func TestMapStopsAfterTimeout(t *testing.T) {
+
+	items := make([]interface{}, 100)
+	var called int32
+
+	promise := Map(items, 1, func(item interface{}) interface{} {
+		atomic.AddInt32(&called, 1)
+		time.Sleep(time.Millisecond)
+		return item
+	})
+	_, err := promise.Timeout(10 * time.Millisecond).Await()
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	_, err = promise.Await()
+	assert.ErrorIs(t, err, ErrTimeout)
+	calls := atomic.LoadInt32(&called)
+	assert.Less(t, calls, int32(100))
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, calls, atomic.LoadInt32(&called))
+}
+
+func TestMapWithContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	items := make([]interface{}, 100)
+	var called int32
+	started := make(chan bool)
+
+	promise := MapWithContext(ctx, items, 1, func(ctx context.Context, item interface{}) interface{} {
+		if atomic.AddInt32(&called, 1) == 1 {
+			close(started)
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	cancel()
+	_, err := promise.Await()
+	time.Sleep(20 * time.Millisecond)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&called))
+}
+
+func TestAllLimitWithContext(t *testing.T) {
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, testStr1)
+
+	value, err := AllLimitWithContext(ctx, 1,
+		func(ctx context.Context, d interface{}) interface{} { return ctx.Value(key{}) },
+		func(ctx context.Context, d interface{}) interface{} { return testStr2 },
+	).Get()
+
+	assert.Equal(t, []interface{}{testStr1, testStr2}, value)
+	assert.NoError(t, err)
+}
+
+func TestMapWithPoolExecutor(t *testing.T) {
+
+	pool := NewPoolExecutor(1)
+	defer pool.Close()
+	SetExecutor(pool)
+	defer SetExecutor(nil)
+
+	value, err := Map([]interface{}{1, 2, 3}, 2, func(item interface{}) interface{} {
+		return item.(int) * 2
+	}).GetWithTimeout(time.Second)
+
+	assert.Equal(t, []interface{}{2, 4, 6}, value)
+	assert.NoError(t, err)
+}
+
+func TestMapCollect(t *testing.T) {
+
+	items := []interface{}{1, 2, 3, 4}
+	var called int32
+	cause := fmt.Errorf(testErr2)
+
+	value, err := MapCollect(items, 2, func(item interface{}) interface{} {
+		atomic.AddInt32(&called, 1)
+		switch item.(int) {
+		case 2:
+			return fmt.Errorf(testErr1)
+		case 4:
+			return cause
+		}
+		return item
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&called))
+
+	var batchErr *BatchError
+	assert.True(t, errors.As(err, &batchErr))
+	assert.True(t, errors.Is(err, cause))
+	assert.NoError(t, batchErr.Errors[0])
+	assert.EqualError(t, batchErr.Errors[1], testErr1)
+	assert.NoError(t, batchErr.Errors[2])
+	assert.EqualError(t, batchErr.Errors[3], testErr2)
+	assert.Contains(t, err.Error(), "2 of 4 failed")
+}
+
+func TestAllLimitCollectWithoutErrors(t *testing.T) {
+
+	value, err := AllLimitCollect(0,
+		func(d interface{}) interface{} { return testStr1 },
+		func(d interface{}) interface{} { return testStr2 },
+	).Get()
+
+	assert.Equal(t, []interface{}{testStr1, testStr2}, value)
+	assert.NoError(t, err)
+}
+
+func TestMapWithoutItems(t *testing.T) {
+
+	value, err := Map(nil, 5, func(item interface{}) interface{} { return item }).Get()
+
+	assert.Equal(t, []interface{}{}, value)
+	assert.NoError(t, err)
+}
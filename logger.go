@@ -0,0 +1,149 @@
+package go_promise
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+/*
+	Level of log message, values are the same as in log/slog
+ */
+type Level int
+
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	return slog.Level(l).String()
+}
+
+/*
+	Structured field of log message
+ */
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+/*
+	Logger of promise lifecycle
+
+	Every message has fields: promise (id), parent (id of parent promise),
+	state and duration (time since creation of promise).
+	Enabled is called before building of fields, that's why disabled level
+	doesn't cost formatting and allocations.
+ */
+type Logger interface {
+	Enabled(level Level) bool
+	Log(level Level, msg string, fields ...Field)
+}
+
+/*
+	Logger which writes nothing, default logger
+ */
+var NopLogger Logger = nopLogger{}
+
+type nopLogger struct{}
+
+func (nopLogger) Enabled(level Level) bool {
+	return false
+}
+
+func (nopLogger) Log(level Level, msg string, fields ...Field) {
+}
+
+/*
+	Adapter for log/slog
+
+	Use:
+		SetLogger(NewSlogLogger(slog.Default()))
+ */
+func NewSlogLogger(logger *slog.Logger) Logger {
+
+	return slogLogger{logger: logger}
+}
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+func (l slogLogger) Enabled(level Level) bool {
+	return l.logger.Enabled(context.Background(), slog.Level(level))
+}
+
+func (l slogLogger) Log(level Level, msg string, fields ...Field) {
+
+	attrs := make([]slog.Attr, len(fields), len(fields))
+	for i, field := range fields {
+		attrs[i] = slog.Any(field.Key, field.Value)
+	}
+	l.logger.LogAttrs(context.Background(), slog.Level(level), msg, attrs...)
+}
+
+type loggerKey struct{}
+
+var logger = struct {
+	sync.RWMutex
+	value Logger
+}{value: NopLogger}
+
+/*
+	Set package-wide logger, it's used by chains without own logger
+
+	nil sets NopLogger.
+ */
+func SetLogger(l Logger) {
+
+	if l == nil {
+		l = NopLogger
+	}
+	logger.Lock()
+	defer logger.Unlock()
+	logger.value = l
+}
+
+/*
+	Logger for chain created by NewPromiseWithContext
+ */
+func WithLogger(ctx context.Context, l Logger) context.Context {
+
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+func loggerFrom(ctx context.Context) Logger {
+
+	if l, ok := ctx.Value(loggerKey{}).(Logger); ok && l != nil {
+		return l
+	}
+	logger.RLock()
+	defer logger.RUnlock()
+	return logger.value
+}
+
+/*
+	Write message with fields of promise, check level by logEnabled
+	before call with additional fields
+ */
+func (p *Promise) log(level Level, msg string, fields ...Field) {
+
+	if !p.logger.Enabled(level) {
+		return
+	}
+	all := make([]Field, 0, 4+len(fields))
+	all = append(all,
+		Field{Key: "promise", Value: p.id},
+		Field{Key: "parent", Value: p.parentId},
+		Field{Key: "state", Value: p.getState()},
+		Field{Key: "duration", Value: p.clock.Now().Sub(p.created)})
+	p.logger.Log(level, msg, append(all, fields...)...)
+}
+
+func (p *Promise) logEnabled(level Level) bool {
+
+	return p.logger.Enabled(level)
+}
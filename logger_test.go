@@ -0,0 +1,122 @@
+package go_promise
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRecord struct {
+	level  Level
+	msg    string
+	fields map[string]interface{}
+}
+
+type testLogger struct {
+	mutex   sync.Mutex
+	level   Level
+	records []testRecord
+}
+
+func (l *testLogger) Enabled(level Level) bool {
+	return level >= l.level
+}
+
+func (l *testLogger) Log(level Level, msg string, fields ...Field) {
+
+	record := testRecord{level: level, msg: msg, fields: make(map[string]interface{})}
+	for _, field := range fields {
+		record.fields[field.Key] = field.Value
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.records = append(l.records, record)
+}
+
+func (l *testLogger) find(msg string) []testRecord {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	result := make([]testRecord, 0)
+	for _, record := range l.records {
+		if record.msg == msg {
+			result = append(result, record)
+		}
+	}
+	return result
+}
+
+func TestLoggerFields(t *testing.T) {
+
+	logger := &testLogger{level: LevelDebug}
+	ctx := WithLogger(context.Background(), logger)
+
+	parent := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 })
+	child := parent.Then(func(d interface{}) interface{} { return testStr2 })
+	_, err := child.Get()
+	assert.NoError(t, err)
+
+	records := logger.find("finalize")
+	assert.Len(t, records, 2)
+	for _, record := range records {
+		assert.Equal(t, LevelDebug, record.level)
+		assert.Equal(t, Success, record.fields["state"])
+		assert.Contains(t, record.fields, "duration")
+	}
+	assert.Equal(t, parent.id, records[0].fields["promise"])
+	assert.Equal(t, "", records[0].fields["parent"])
+	assert.Equal(t, child.id, records[1].fields["promise"])
+	assert.Equal(t, parent.id, records[1].fields["parent"])
+}
+
+func TestLoggerLevel(t *testing.T) {
+
+	logger := &testLogger{level: LevelWarn}
+	ctx := WithLogger(context.Background(), logger)
+
+	_, err := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 }).
+		Then(func(d interface{}) interface{} { panic(testStr2) }).
+		Get()
+	assert.Error(t, err)
+
+	assert.Len(t, logger.find("finalize"), 0)
+	records := logger.find("handler panic")
+	assert.Len(t, records, 1)
+	assert.Equal(t, testStr2, records[0].fields["panic"])
+}
+
+func TestDisabledLoggerWithoutAllocations(t *testing.T) {
+
+	promise := Resolve(testStr1)
+	<-promise.Done()
+	err := fmt.Errorf(testErr1)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		promise.log(LevelDebug, "message")
+		if promise.logEnabled(LevelDebug) {
+			promise.log(LevelDebug, "message", Field{Key: "error", Value: err})
+		}
+	})
+	assert.Equal(t, float64(0), allocs)
+}
+
+func TestSlogLogger(t *testing.T) {
+
+	buffer := &bytes.Buffer{}
+	logger := NewSlogLogger(slog.New(slog.NewTextHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	SetLogger(logger)
+	defer SetLogger(nil)
+
+	promise := Resolve(testStr1)
+	_, err := promise.Get()
+	assert.NoError(t, err)
+
+	assert.Contains(t, buffer.String(), "msg=finalize promise="+promise.id)
+	assert.Contains(t, buffer.String(), "state=success")
+	assert.Equal(t, "DEBUG", LevelDebug.String())
+}
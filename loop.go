@@ -0,0 +1,94 @@
+package go_promise
+
+import "sync"
+
+/*
+	Executor with microtask queue like job queue of ECMA-262
+
+	Execute only adds job to queue, RunUntilIdle executes jobs in order
+	of addition in goroutine of caller until queue is empty. Handlers of chain
+	are called one by one in the same order as reactions of JS, so the same
+	chain gives the same interleaving every run.
+
+	Handler of NewPromise is a job too, it's like Promise.resolve().then(handler).
+	Handlers mustn't wait other promises of loop by Get or Await, because
+	they are executed by the same goroutine; return promise instead.
+	Combinators All, AllSettled, Race, Any, AllLimit, Map and Retry don't wait
+	by goroutine, so they can be used with loop. Timers of Retry, Delay and Timeout
+	are fired by clock, their jobs are executed by next RunUntilIdle.
+
+	Use:
+		loop := NewEventLoop()
+		ctx := WithExecutor(context.Background(), loop)
+		promise := NewPromiseWithContext(ctx, ...).Then(...)
+		loop.RunUntilIdle()
+		value, err := promise.Value(), promise.Err()
+ */
+type EventLoop struct {
+	mutex   sync.Mutex
+	jobs    []func()
+	running bool
+}
+
+func NewEventLoop() *EventLoop {
+
+	return &EventLoop{}
+}
+
+/*
+	Add job to the end of queue, it's safe for any goroutine
+ */
+func (l *EventLoop) Execute(job func()) {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.jobs = append(l.jobs, job)
+}
+
+/*
+	Execute jobs until queue is empty, returns count of executed jobs
+
+	Jobs added during run are executed by the same run.
+	Nested call from job returns 0 without execution.
+ */
+func (l *EventLoop) RunUntilIdle() int {
+
+	l.mutex.Lock()
+	if l.running {
+		l.mutex.Unlock()
+		return 0
+	}
+	l.running = true
+	l.mutex.Unlock()
+	defer func() {
+		l.mutex.Lock()
+		l.running = false
+		l.mutex.Unlock()
+	}()
+
+	count := 0
+	for {
+		l.mutex.Lock()
+		if len(l.jobs) == 0 {
+			l.mutex.Unlock()
+			return count
+		}
+		job := l.jobs[0]
+		l.jobs[0] = nil
+		l.jobs = l.jobs[1:]
+		l.mutex.Unlock()
+
+		job()
+		count++
+	}
+}
+
+/*
+	Count of jobs in queue
+ */
+func (l *EventLoop) Pending() int {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.jobs)
+}
@@ -0,0 +1,182 @@
+package go_promise
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLog struct {
+	mutex sync.Mutex
+	items []string
+}
+
+func (l *testLog) add(item string) func(d interface{}) interface{} {
+
+	return func(d interface{}) interface{} {
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		l.items = append(l.items, item)
+		return d
+	}
+}
+
+func (l *testLog) String() string {
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return strings.Join(l.items, " ")
+}
+
+func loopRoot(ctx context.Context) *Promise {
+
+	return NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return nil })
+}
+
+func TestEventLoopInterleaving(t *testing.T) {
+
+	loop := NewEventLoop()
+	ctx := WithExecutor(context.Background(), loop)
+	log := &testLog{}
+
+	loopRoot(ctx).Then(log.add("a1")).Then(log.add("a2")).Then(log.add("a3"))
+	loopRoot(ctx).Then(log.add("b1")).Then(log.add("b2")).Then(log.add("b3"))
+	assert.Equal(t, "", log.String())
+
+	loop.RunUntilIdle()
+	// the same as JS
+	assert.Equal(t, "a1 b1 a2 b2 a3 b3", log.String())
+}
+
+func TestEventLoopAdoptionTicks(t *testing.T) {
+
+	loop := NewEventLoop()
+	ctx := WithExecutor(context.Background(), loop)
+	log := &testLog{}
+
+	loopRoot(ctx).
+		Then(func(d interface{}) interface{} {
+			log.add("a1")(d)
+			resolved := NewDeferredWithContext(ctx)
+			resolved.Resolve(nil)
+			return resolved.Promise
+		}).
+		Then(log.add("a2"))
+	loopRoot(ctx).Then(log.add("b1")).Then(log.add("b2")).Then(log.add("b3")).Then(log.add("b4"))
+	loop.RunUntilIdle()
+
+	// returned promise costs two jobs like in JS
+	assert.Equal(t, "a1 b1 b2 b3 a2 b4", log.String())
+}
+
+func TestEventLoopReactionsOrder(t *testing.T) {
+
+	loop := NewEventLoop()
+	ctx := WithExecutor(context.Background(), loop)
+	log := &testLog{}
+
+	root := loopRoot(ctx)
+	root.Then(log.add("1"))
+	root.Then(log.add("2")).Then(log.add("4"))
+	root.Then(log.add("3"))
+	loop.RunUntilIdle()
+
+	// late reaction of settled promise
+	root.Then(log.add("5"))
+	loop.RunUntilIdle()
+
+	assert.Equal(t, "1 2 3 4 5", log.String())
+}
+
+func TestEventLoopDeterministic(t *testing.T) {
+
+	run := func() string {
+		loop := NewEventLoop()
+		ctx := WithExecutor(context.Background(), loop)
+		log := &testLog{}
+		for i := 0; i < 5; i++ {
+			p := loopRoot(ctx)
+			for j := 0; j < 3; j++ {
+				p = p.Then(log.add(fmt.Sprintf("%d.%d", i, j)))
+			}
+		}
+		loop.RunUntilIdle()
+		return log.String()
+	}
+
+	first := run()
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, first, run())
+	}
+}
+
+func TestEventLoopCombinators(t *testing.T) {
+
+	loop := NewEventLoop()
+	ctx := WithExecutor(context.Background(), loop)
+
+	first := loopRoot(ctx).Then(func(d interface{}) interface{} { return testStr1 })
+	second := loopRoot(ctx).Then(func(d interface{}) interface{} { return fmt.Errorf(testErr1) })
+	settled := AllSettled(first, second)
+	race := Race(second, first)
+	loop.RunUntilIdle()
+
+	// goroutines of GoExecutor settle promises of combinators
+	_, err := settled.Await()
+	assert.NoError(t, err)
+	// first is settled by the earlier job
+	value, err := race.Await()
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestEventLoopLimitAndRetry(t *testing.T) {
+
+	loop := NewEventLoop()
+	SetExecutor(loop)
+	defer SetExecutor(nil)
+	SetClock(NewFakeClock(time.Now()))
+	defer SetClock(nil)
+
+	double := func(item interface{}) interface{} { return item.(int) * 2 }
+	mapped := Map([]interface{}{1, 2, 3}, 1, double)
+	collected := MapCollect([]interface{}{1, 2}, 1, func(item interface{}) interface{} {
+		return fmt.Errorf(testErr1)
+	})
+	limited := AllLimit(1,
+		func(d interface{}) interface{} { return testStr1 },
+		func(d interface{}) interface{} { return Resolve(testStr2) },
+	)
+	retried := Retry(RetryPolicy{MaxAttempts: 3}, func(attempt int) interface{} {
+		if attempt < 3 {
+			return fmt.Errorf(testErr1)
+		}
+		return attempt
+	})
+	loop.RunUntilIdle()
+
+	assert.Equal(t, []interface{}{2, 4, 6}, mapped.Value())
+	assert.ErrorContains(t, collected.Err(), "2 of 2 failed")
+	assert.Equal(t, []interface{}{testStr1, testStr2}, limited.Value())
+	assert.Equal(t, 3, retried.Value())
+}
+
+func TestEventLoopRunUntilIdle(t *testing.T) {
+
+	loop := NewEventLoop()
+	nested := -1
+	loop.Execute(func() {
+		loop.Execute(func() {})
+		nested = loop.RunUntilIdle()
+	})
+
+	assert.Equal(t, 1, loop.Pending())
+	assert.Equal(t, 2, loop.RunUntilIdle())
+	assert.Equal(t, 0, nested)
+	assert.Equal(t, 0, loop.Pending())
+}
@@ -0,0 +1,292 @@
+package go_promise
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/*
+	Default bounds of latency histograms in seconds
+ */
+var DefaultBuckets = []float64{.0005, .001, .005, .01, .05, .1, .5, 1, 5, 10}
+
+/*
+	Metrics of promises, it's Hooks
+
+	Counters: created, fulfilled, rejected, timed out (rejected by Timeout),
+	panicked (panic of handler) and wait timeouts (GetWithTimeout returns TimeoutError).
+	Histograms: latency of handlers and time from creation to settlement.
+	Gauge: count of pending promises.
+
+	Metrics is expvar.Var and can be exported by Prometheus text format.
+
+	Use:
+		metrics := NewMetrics()
+		RegisterHooks(metrics)
+		expvar.Publish("promise", metrics)
+		http.Handle("/metrics", metrics.Handler())
+ */
+type Metrics struct {
+	mutex        sync.Mutex
+	clock        Clock
+	promises     map[string]*promiseMetrics
+	created      uint64
+	fulfilled    uint64
+	rejected     uint64
+	timedOut     uint64
+	panicked     uint64
+	waitTimeouts uint64
+	handler      *Histogram
+	settle       *Histogram
+}
+
+/*
+	created - time of creation
+	handlerStart - time of start of running handler
+	panic - last counted panic of promise
+ */
+type promiseMetrics struct {
+	created      time.Time
+	handlerStart time.Time
+	panic        *PanicError
+}
+
+/*
+	Copy of metrics
+ */
+type MetricsSnapshot struct {
+	Created         uint64            `json:"created"`
+	Fulfilled       uint64            `json:"fulfilled"`
+	Rejected        uint64            `json:"rejected"`
+	TimedOut        uint64            `json:"timed_out"`
+	Panicked        uint64            `json:"panicked"`
+	WaitTimeouts    uint64            `json:"wait_timeouts"`
+	Pending         int               `json:"pending"`
+	HandlerDuration HistogramSnapshot `json:"handler_duration_seconds"`
+	SettleDuration  HistogramSnapshot `json:"settle_duration_seconds"`
+}
+
+func NewMetrics() *Metrics {
+
+	return NewMetricsWithClock(RealClock, DefaultBuckets)
+}
+
+/*
+	Metrics with own clock and bounds of histograms in seconds
+ */
+func NewMetricsWithClock(clock Clock, buckets []float64) *Metrics {
+
+	return &Metrics{
+		clock:    clock,
+		promises: make(map[string]*promiseMetrics),
+		handler:  NewHistogram(buckets),
+		settle:   NewHistogram(buckets),
+	}
+}
+
+func (m *Metrics) OnCreate(id, parentId string) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.created++
+	m.promises[id] = &promiseMetrics{created: m.clock.Now()}
+}
+
+func (m *Metrics) OnHandlerStart(id, label string) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if pm, ok := m.promises[id]; ok {
+		pm.handlerStart = m.clock.Now()
+	}
+}
+
+func (m *Metrics) OnHandlerEnd(id string, err error) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	pm, ok := m.promises[id]
+	if !ok {
+		return
+	}
+	if !pm.handlerStart.IsZero() {
+		m.handler.Observe(m.clock.Now().Sub(pm.handlerStart).Seconds())
+		pm.handlerStart = time.Time{}
+	}
+	// panic error is transferred to children and their catch handlers, only panic of own handler is counted
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) && panicErr.Id == id && panicErr != pm.panic {
+		pm.panic = panicErr
+		m.panicked++
+	}
+}
+
+func (m *Metrics) OnSettle(id string, state State, err error) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	pm, ok := m.promises[id]
+	if !ok {
+		// promise is created before registration of metrics
+		return
+	}
+	delete(m.promises, id)
+	m.settle.Observe(m.clock.Now().Sub(pm.created).Seconds())
+	if state == Success {
+		m.fulfilled++
+		return
+	}
+	m.rejected++
+	// timeout error is transferred to children, only promise of Timeout is counted
+	var timeoutErr *TimeoutError
+	if errors.As(err, &timeoutErr) && timeoutErr.rejectedId == id {
+		m.timedOut++
+	}
+}
+
+func (m *Metrics) OnChildAttached(parentId, childId string) {
+}
+
+func (m *Metrics) OnWaitTimeout(id string, timeout time.Duration) {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.waitTimeouts++
+}
+
+func (m *Metrics) Snapshot() MetricsSnapshot {
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return MetricsSnapshot{
+		Created:         m.created,
+		Fulfilled:       m.fulfilled,
+		Rejected:        m.rejected,
+		TimedOut:        m.timedOut,
+		Panicked:        m.panicked,
+		WaitTimeouts:    m.waitTimeouts,
+		Pending:         len(m.promises),
+		HandlerDuration: m.handler.Snapshot(),
+		SettleDuration:  m.settle.Snapshot(),
+	}
+}
+
+/*
+	JSON of snapshot for expvar
+ */
+func (m *Metrics) String() string {
+
+	data, err := json.Marshal(m.Snapshot())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+/*
+	Handler of Prometheus text format, all metrics have prefix "promise_"
+ */
+func (m *Metrics) Handler() http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		s := m.Snapshot()
+		writeCounter(w, "promise_created_total", "Created promises.", s.Created)
+		writeCounter(w, "promise_fulfilled_total", "Fulfilled promises.", s.Fulfilled)
+		writeCounter(w, "promise_rejected_total", "Rejected promises.", s.Rejected)
+		writeCounter(w, "promise_timed_out_total", "Promises rejected by timeout.", s.TimedOut)
+		writeCounter(w, "promise_panicked_total", "Panics of handlers.", s.Panicked)
+		writeCounter(w, "promise_wait_timeouts_total", "Timeouts of GetWithTimeout.", s.WaitTimeouts)
+		fmt.Fprintf(w, "# HELP promise_pending Pending promises.\n# TYPE promise_pending gauge\npromise_pending %d\n", s.Pending)
+		writeHistogram(w, "promise_handler_duration_seconds", "Latency of handlers.", s.HandlerDuration)
+		writeHistogram(w, "promise_settle_duration_seconds", "Time from creation to settlement.", s.SettleDuration)
+	})
+}
+
+func writeCounter(w http.ResponseWriter, name, help string, value uint64) {
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, value)
+}
+
+func writeHistogram(w http.ResponseWriter, name, help string, h HistogramSnapshot) {
+
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for i, bound := range h.Bounds {
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, formatFloat(bound), h.Counts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, h.Count)
+	fmt.Fprintf(w, "%s_sum %s\n%s_count %d\n", name, formatFloat(h.Sum), name, h.Count)
+}
+
+func formatFloat(f float64) string {
+
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+/*
+	Histogram with fixed upper bounds of buckets
+ */
+type Histogram struct {
+	mutex  sync.Mutex
+	bounds []float64
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+/*
+	Cumulative copy of histogram like Prometheus: Counts[i] is count of values <= Bounds[i]
+ */
+type HistogramSnapshot struct {
+	Bounds []float64 `json:"bounds"`
+	Counts []uint64  `json:"counts"`
+	Sum    float64   `json:"sum"`
+	Count  uint64    `json:"count"`
+}
+
+func NewHistogram(bounds []float64) *Histogram {
+
+	sorted := append([]float64(nil), bounds...)
+	sort.Float64s(sorted)
+	return &Histogram{bounds: sorted, counts: make([]uint64, len(sorted), len(sorted))}
+}
+
+func (h *Histogram) Observe(value float64) {
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	i := sort.SearchFloat64s(h.bounds, value)
+	if i < len(h.counts) {
+		h.counts[i]++
+	}
+	h.sum += value
+	h.count++
+}
+
+func (h *Histogram) Snapshot() HistogramSnapshot {
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	s := HistogramSnapshot{
+		Bounds: append([]float64(nil), h.bounds...),
+		Counts: make([]uint64, len(h.counts), len(h.counts)),
+		Sum:    h.sum,
+		Count:  h.count,
+	}
+	var total uint64
+	for i, count := range h.counts {
+		total += count
+		s.Counts[i] = total
+	}
+	return s
+}
@@ -0,0 +1,181 @@
+package go_promise
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsCounters(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	metrics := NewMetricsWithClock(clock, []float64{.01, .1, 1})
+	ctx := WithHooks(context.Background(), metrics)
+
+	parent := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		clock.Advance(50 * time.Millisecond)
+		return testStr1
+	})
+	parent.Then(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).Await()
+	parent.Then(func(d interface{}) interface{} { panic(testErr1) }).Await()
+
+	s := metrics.Snapshot()
+	assert.Equal(t, uint64(3), s.Created)
+	assert.Equal(t, uint64(1), s.Fulfilled)
+	assert.Equal(t, uint64(2), s.Rejected)
+	assert.Equal(t, uint64(1), s.Panicked)
+	assert.Equal(t, uint64(0), s.TimedOut)
+	assert.Equal(t, 0, s.Pending)
+	assert.Equal(t, uint64(3), s.SettleDuration.Count)
+}
+
+func TestMetricsTimeoutAndPending(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	metrics := NewMetricsWithClock(clock, DefaultBuckets)
+	ctx := WithClock(WithHooks(context.Background(), metrics), clock)
+
+	release := make(chan struct{})
+	defer close(release)
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		<-release
+		return testStr1
+	})
+	timeout := promise.Timeout(time.Second)
+	clock.BlockUntil(1)
+	assert.Equal(t, 2, metrics.Snapshot().Pending)
+
+	clock.Advance(time.Second)
+	_, err := timeout.Await()
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	s := metrics.Snapshot()
+	assert.Equal(t, uint64(1), s.TimedOut)
+	assert.Equal(t, 1, s.Pending)
+	assert.Equal(t, uint64(1), s.SettleDuration.Count)
+	assert.Equal(t, 1.0, s.SettleDuration.Sum)
+}
+
+func TestMetricsChainedTimeout(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	metrics := NewMetricsWithClock(clock, DefaultBuckets)
+	ctx := WithClock(WithHooks(context.Background(), metrics), clock)
+
+	deferred := NewDeferredWithContext(ctx)
+	last := deferred.Promise.Timeout(10 * time.Millisecond).Then(nil).Then(nil).Then(nil)
+	clock.Advance(10 * time.Millisecond)
+	_, err := last.Await()
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	s := metrics.Snapshot()
+	assert.Equal(t, uint64(1), s.TimedOut)
+	assert.Equal(t, uint64(4), s.Rejected)
+	deferred.Resolve(testStr1)
+}
+
+func TestMetricsManyPromises(t *testing.T) {
+
+	metrics := NewMetrics()
+	ctx := WithHooks(context.Background(), metrics)
+
+	deferreds := make([]*Deferred, 20000)
+	for i := range deferreds {
+		deferreds[i] = NewDeferredWithContext(ctx)
+	}
+	assert.Equal(t, len(deferreds), metrics.Snapshot().Pending)
+	for _, deferred := range deferreds {
+		deferred.Resolve(testStr1)
+	}
+
+	s := metrics.Snapshot()
+	assert.Equal(t, uint64(len(deferreds)), s.Created)
+	assert.Equal(t, s.Created, s.Fulfilled)
+	assert.Equal(t, 0, s.Pending)
+}
+
+func TestMetricsWaitTimeout(t *testing.T) {
+
+	metrics := NewMetrics()
+	ctx := WithHooks(context.Background(), metrics)
+
+	release := make(chan struct{})
+	defer close(release)
+	_, err := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		<-release
+		return testStr1
+	}).GetWithTimeout(time.Millisecond)
+
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, uint64(1), metrics.Snapshot().WaitTimeouts)
+}
+
+func TestMetricsHandlerLatency(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	metrics := NewMetricsWithClock(clock, []float64{.01, .1, 1})
+	ctx := WithHooks(context.Background(), metrics)
+
+	NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} {
+		clock.Advance(50 * time.Millisecond)
+		return testStr1
+	}).Await()
+
+	s := metrics.Snapshot().HandlerDuration
+	assert.Equal(t, []uint64{0, 1, 1}, s.Counts)
+	assert.Equal(t, uint64(1), s.Count)
+	assert.InDelta(t, 0.05, s.Sum, 1e-9)
+}
+
+func TestMetricsExpvar(t *testing.T) {
+
+	metrics := NewMetrics()
+	NewPromiseWithContext(WithHooks(context.Background(), metrics),
+		func(ctx context.Context, d interface{}) interface{} { return testStr1 }).Await()
+
+	var s MetricsSnapshot
+	assert.NoError(t, json.Unmarshal([]byte(metrics.String()), &s))
+	assert.Equal(t, uint64(1), s.Created)
+	assert.Equal(t, uint64(1), s.Fulfilled)
+}
+
+func TestMetricsPrometheus(t *testing.T) {
+
+	metrics := NewMetricsWithClock(RealClock, []float64{1})
+	NewPromiseWithContext(WithHooks(context.Background(), metrics),
+		func(ctx context.Context, d interface{}) interface{} { return testStr1 }).Await()
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(w.Result().Body)
+	text := string(body)
+
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain"))
+	assert.Contains(t, text, "# TYPE promise_created_total counter\npromise_created_total 1\n")
+	assert.Contains(t, text, "promise_pending 0\n")
+	assert.Contains(t, text, "promise_settle_duration_seconds_bucket{le=\"1\"} 1\n")
+	assert.Contains(t, text, "promise_settle_duration_seconds_bucket{le=\"+Inf\"} 1\n")
+	assert.Contains(t, text, "promise_handler_duration_seconds_count 1\n")
+}
+
+func TestHistogram(t *testing.T) {
+
+	h := NewHistogram([]float64{1, 0.1})
+	h.Observe(0.05)
+	h.Observe(0.1)
+	h.Observe(0.5)
+	h.Observe(2)
+
+	s := h.Snapshot()
+	assert.Equal(t, []float64{0.1, 1}, s.Bounds)
+	assert.Equal(t, []uint64{2, 3}, s.Counts)
+	assert.Equal(t, uint64(4), s.Count)
+	assert.InDelta(t, 2.65, s.Sum, 1e-9)
+}
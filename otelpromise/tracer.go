@@ -0,0 +1,100 @@
+/*
+	OpenTelemetry adapter for tracing of go_promise
+
+	Use:
+		go_promise.SetTracer(otelpromise.NewTracer(otel.Tracer("app")))
+
+	Span of parent promise is child of span from context of chain,
+	context-aware handlers get context with span of their promise.
+
+	Package is separate module, so core package doesn't depend on OpenTelemetry.
+ */
+package otelpromise
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	promise "github.com/danevge/go-promise"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracer struct {
+	tracer trace.Tracer
+}
+
+/*
+	Wrap OpenTelemetry tracer
+ */
+func NewTracer(t trace.Tracer) promise.Tracer {
+
+	return tracer{tracer: t}
+}
+
+func (t tracer) Start(ctx context.Context, parent promise.Span, name string) promise.Span {
+
+	if s, ok := parent.(*span); ok {
+		ctx = s.ctx
+	}
+	ctx, s := t.tracer.Start(ctx, name)
+	return &span{ctx: ctx, span: s}
+}
+
+/*
+	ctx - context with span, it's parent for spans of child promises
+ */
+type span struct {
+	ctx  context.Context
+	span trace.Span
+}
+
+func (s *span) SetName(name string) {
+	s.span.SetName(name)
+}
+
+func (s *span) SetAttribute(key string, value interface{}) {
+	s.span.SetAttributes(attributeOf(key, value))
+}
+
+/*
+	Every error marks span as failed
+ */
+func (s *span) RecordError(err error) {
+
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s *span) End() {
+	s.span.End()
+}
+
+func (s *span) ContextWithSpan(ctx context.Context) context.Context {
+
+	return trace.ContextWithSpan(ctx, s.span)
+}
+
+func attributeOf(key string, value interface{}) attribute.KeyValue {
+
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case time.Duration:
+		return attribute.Int64(key, int64(v))
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
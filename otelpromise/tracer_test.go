@@ -0,0 +1,75 @@
+package otelpromise
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	promise "github.com/danevge/go-promise"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	testStr1 = "aaa_a-1"
+	testErr1 = "ups 1"
+)
+
+func newTestTracer() (*tracetest.SpanRecorder, trace.Tracer) {
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	return recorder, provider.Tracer("test")
+}
+
+func TestOtelSpansOfChain(t *testing.T) {
+
+	recorder, otelTracer := newTestTracer()
+	ctx, root := otelTracer.Start(context.Background(), "request")
+	ctx = promise.WithTracer(ctx, NewTracer(otelTracer))
+
+	var handlerSpan trace.SpanContext
+	_, err := promise.NewPromiseWithContext(ctx, func(ctx context.Context, value interface{}) interface{} {
+		handlerSpan = trace.SpanContextFromContext(ctx)
+		return testStr1
+	}).Label("load").
+		Then(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).Label("save").
+		Await()
+	root.End()
+	assert.EqualError(t, err, testErr1)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 3)
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+	load, save := byName["load"], byName["save"]
+	assert.Equal(t, root.SpanContext().SpanID(), load.Parent().SpanID())
+	assert.Equal(t, load.SpanContext().SpanID(), save.Parent().SpanID())
+	assert.Equal(t, load.SpanContext().SpanID(), handlerSpan.SpanID())
+	assert.Equal(t, codes.Unset, load.Status().Code)
+	assert.Equal(t, codes.Error, save.Status().Code)
+	assert.Len(t, save.Events(), 1)
+}
+
+func TestOtelAttributes(t *testing.T) {
+
+	recorder, otelTracer := newTestTracer()
+	ctx := promise.WithTracer(context.Background(), NewTracer(otelTracer))
+
+	p := promise.NewPromiseWithContext(ctx, func(ctx context.Context, value interface{}) interface{} {
+		return testStr1
+	})
+	p.Await()
+
+	attributes := map[string]string{}
+	for _, kv := range recorder.Ended()[0].Attributes() {
+		attributes[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "success", attributes["promise.state"])
+	assert.NotEmpty(t, attributes["promise.id"])
+}
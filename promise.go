@@ -1,49 +1,153 @@
 package go_promise
 
 import (
-	"log"
+	"context"
+	"encoding/json"
 	"time"
 	"fmt"
+	"sync"
 )
 
 const defaultTimeout = 250 * time.Millisecond
 
-type TimeoutError error
-type state int
+/*
+	Promise state
+
+	Pending - promise isn't settled
+	Success - promise is fulfilled by value
+	Rejected - promise is rejected by error
+ */
+type State int
 
 const (
-	_        state = iota
-	pending
-	success
-	rejected
+	_       State = iota
+	Pending
+	Success
+	Rejected
 )
 
+func (s State) String() string {
+
+	switch s {
+	case Pending:
+		return "pending"
+	case Success:
+		return "success"
+	case Rejected:
+		return "rejected"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+/*
+	State is string in JSON
+ */
+func (s State) MarshalJSON() ([]byte, error) {
+
+	return json.Marshal(s.String())
+}
+
+func (s *State) UnmarshalJSON(data []byte) error {
+
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	for _, state := range []State{Pending, Success, Rejected} {
+		if state.String() == name {
+			*s = state
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown promise state %q", name)
+}
+
 /*
 	id - param for logging
+	parentId - id of parent promise, empty for parent promise
+	created - time of creation for logging
+	ctx - context of chain, its cancellation rejects all pending promises
+	mutex - guard of state and result (pointer, because resolve copies Promise)
 	state - promise state
 	result - end result all process for promise
 	onSuccess - main function
 	onReject - resolve error function
 	final - broadcast about finalize all process about build end result
+	reactions - functions which are called by finalize in order of subscription
+	self - link to promise for its copies, resolve uses it for Promise value
+	adopting - new promise which result is awaited, it's guarded by adoption
+	executor - runs handlers of chain
+	clock - source of time for timeouts of chain
+	logger - logger of chain
+	hooks - lifecycle hooks of chain
+	label - name of promise for hooks and tracing
+	span - span of promise, nil without tracer
+	spanNamed - span has got name of handler
+	spanErr - error of handler recorded on span
+	stopWatch - stop watching for ctx cancellation
+	timer - timer of delayed settlement, it's stopped by finalize
+	handled - promise has handler or its result is got, see OnUnhandledRejection
+	reported - rejection is reported as unhandled
+	cancelHandler - cancel context of running context-aware handler
+	abortCause - reason of cancellation for not started context-aware handler
  */
 type Promise struct {
 	id        string
-	state     state
+	parentId  string
+	created   time.Time
+	ctx       context.Context
+	mutex     *sync.Mutex
+	state     State
 	result    *result
 	onSuccess func(value interface{}) interface{}
 	onReject  func(err error) interface{}
-	final     chan bool
+	final     chan struct{}
+	reactions []func()
+	self      *Promise
+	adopting  *Promise
+	executor  Executor
+	clock     Clock
+	logger    Logger
+	hooks     []Hooks
+	label     string
+	span      Span
+	spanNamed bool
+	spanErr   error
+	stopWatch func() bool
+	timer     Timer
+	handled   bool
+	reported  bool
+
+	cancelHandler context.CancelCauseFunc
+	abortCause    error
 }
 
 /*
 	Add new promise with handler for current promise
 
+	nil handler transfers value without changes.
 	JS example: promise.then( result => { ... });
  */
 func (p *Promise) Then(onSuccess func(value interface{}) interface{}) *Promise {
 
 	promise := newPromise(p)
-	promise.onSuccess = onSuccess
+	if onSuccess != nil {
+		promise.onSuccess = onSuccess
+	}
+	p.add(promise)
+	return promise
+}
+
+/*
+	Add new promise with context-aware handler for current promise
+
+	Handler gets context of chain and can stop work by ctx.Done().
+ */
+func (p *Promise) ThenWithContext(onSuccess func(ctx context.Context, value interface{}) interface{}) *Promise {
+
+	promise := newPromise(p)
+	promise.onSuccess = withContext(promise, onSuccess)
 	p.add(promise)
 	return promise
 }
@@ -51,6 +155,8 @@ func (p *Promise) Then(onSuccess func(value interface{}) interface{}) *Promise {
 /*
 	Add new promise with handler and catch handler for current promise
 
+	onRejected is called only for error of current promise, error of onSuccess
+	rejects new promise. nil handlers transfer value or error without changes.
 	JS example:
 					promise.then(
 						result => ...,
@@ -61,26 +167,85 @@ func (p *Promise) ThenAndCatch(onSuccess func(value interface{}) interface{},
 	onRejected func(err error) interface{}) *Promise {
 
 	promise := newPromise(p)
-	promise.onSuccess = onSuccess
-	promise.onReject = onRejected
+	if onSuccess != nil {
+		promise.onSuccess = onSuccess
+	}
+	if onRejected != nil {
+		promise.onReject = onRejected
+	}
 	p.add(promise)
 	return promise
 }
 
 /*
-	Add catch handler for current promise
+	Add new promise with catch handler for current promise
 
+	Value of current promise is transferred without changes.
 	JS example: promise.catch(error => { ... });
  */
 func (p *Promise) Catch(onRejected func(err error) interface{}) *Promise {
 
-	p.onReject = onRejected
-	return p
+	return p.ThenAndCatch(nil, onRejected)
+}
+
+/*
+	Reject pending promise by CancelledError
+
+	Context-aware handler of promise gets cancelled context,
+	child promises get this error like other errors.
+ */
+func (p *Promise) Cancel() {
+
+	err := &CancelledError{Id: p.id, Cause: context.Canceled}
+	p.abort(err)
+	p.finalize(Rejected, &result{
+		resultType: ERROR,
+		err:        err,
+	})
+}
+
+/*
+	Add new promise with handler which is called after settlement of current promise
+
+	Value or error of current promise is transferred without changes,
+	but panic of handler rejects new promise by PanicError.
+	Handler isn't called if chain is cancelled before settlement.
+	JS example: promise.finally(() => { ... });
+ */
+func (p *Promise) Finally(onFinally func()) *Promise {
+
+	return p.FinallyAsync(func() *Promise {
+		onFinally()
+		return nil
+	})
+}
+
+/*
+	Like Finally, but waits promise returned by handler
+
+	If returned promise is rejected then its error rejects new promise.
+ */
+func (p *Promise) FinallyAsync(onFinally func() *Promise) *Promise {
+
+	// value or error of current promise after settlement of promise of handler
+	finally := func(then interface{}) interface{} {
+		promise := onFinally()
+		if promise == nil {
+			return then
+		}
+		return promise.Then(func(value interface{}) interface{} { return then })
+	}
+
+	return p.ThenAndCatch(
+		func(value interface{}) interface{} { return finally(value) },
+		func(err error) interface{} { return finally(err) })
 }
 
 /*
 	adaptation for backend
 	return current value or timeout error
+
+	Default timeout is 250ms, use Await for long processes.
  */
 func (p *Promise) Get() (interface{}, error) {
 
@@ -93,18 +258,124 @@ func (p *Promise) Get() (interface{}, error) {
 func (p *Promise) GetWithTimeout(timeout time.Duration) (interface{}, error) {
 
 	select {
-	case _, ok := <-p.final:
-		if !ok {
-			return p.result.value, p.result.err
+	case <-p.final:
+		p.markHandled()
+		return p.result.value, p.result.err
+	default:
+	}
+
+	// stopped timer isn't kept by clock after settlement
+	expired := make(chan struct{})
+	timer := p.clock.AfterFunc(timeout, func() { close(expired) })
+	defer timer.Stop()
+	select {
+	case <-p.final:
+		p.markHandled()
+		return p.result.value, p.result.err
+	case <-expired:
+		p.hookWaitTimeout(timeout)
+		return nil, &TimeoutError{Id: p.id, Timeout: timeout}
+	}
+}
+
+/*
+	Wait result without timeout
+
+	JS example: const value = await promise;
+ */
+func (p *Promise) Await() (interface{}, error) {
+
+	<-p.final
+	p.markHandled()
+	return p.result.value, p.result.err
+}
+
+/*
+	Wait result until ctx is done, returns CancelledError if ctx is done first
+ */
+func (p *Promise) AwaitContext(ctx context.Context) (interface{}, error) {
+
+	select {
+	case <-p.final:
+		p.markHandled()
+		return p.result.value, p.result.err
+	case <-ctx.Done():
+		return nil, &CancelledError{Id: p.id, Cause: ctx.Err()}
+	}
+}
+
+/*
+	Channel is closed when promise is settled
+
+	Use:
+		select {
+		case <-promise.Done():
+			value, err := promise.Await()
+		case <-time.After(time.Second):
 		}
-	case <-time.After(timeout):
-		return nil, fmt.Errorf("timeout error")
+ */
+func (p *Promise) Done() <-chan struct{} {
+
+	return p.final
+}
+
+/*
+	Current state without waiting
+ */
+func (p *Promise) State() State {
+
+	return p.getState()
+}
+
+/*
+	Value of fulfilled promise or nil without waiting
+ */
+func (p *Promise) Value() interface{} {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.state != Success {
+		return nil
+	}
+	return p.result.value
+}
+
+/*
+	Error of rejected promise or nil without waiting
+
+	Got error marks promise as handled.
+ */
+func (p *Promise) Err() error {
+
+	p.mutex.Lock()
+	if p.state != Rejected {
+		p.mutex.Unlock()
+		return nil
 	}
-	return nil, TimeoutError(fmt.Errorf("timeout error"))
+	err := p.result.err
+	p.mutex.Unlock()
+	p.markHandled()
+	return err
+}
+
+/*
+	Id of promise, it's used by errors, logs, hooks and etc.
+ */
+func (p *Promise) Id() string {
+
+	return p.id
+}
+
+/*
+	Context of chain
+ */
+func (p *Promise) Context() context.Context {
+
+	return p.ctx
 }
 
 func (p *Promise) String() string {
-	return fmt.Sprintf("Promise[id: %v; state: %v]", p.id, p.state)
+	return fmt.Sprintf("Promise[id: %v; state: %v]", p.id, p.getState())
 }
 
 /*
@@ -123,93 +394,315 @@ func defaultOnSuccess(value interface{}) interface{} {
 	return value
 }
 
+/*
+	Child promise inherits context of parent
+ */
 func newPromise(parent *Promise) *Promise {
 
-	oldId := ""
+	if parent == nil {
+		return newContextPromise(context.Background(), nil)
+	}
+	return newContextPromise(parent.ctx, parent)
+}
+
+/*
+	parent is nil for parent promise
+ */
+func newContextPromise(ctx context.Context, parent *Promise) *Promise {
+
+	parentId := ""
 	if parent != nil {
-		oldId = parent.id
+		parentId = parent.id
 	}
-	return &Promise{
-		id:        id(oldId),
-		state:     pending,
+	clock := clockFrom(ctx)
+	promise := &Promise{
+		id:        id(parentId),
+		parentId:  parentId,
+		created:   clock.Now(),
+		ctx:       ctx,
+		mutex:     new(sync.Mutex),
+		state:     Pending,
 		onSuccess: defaultOnSuccess,
 		onReject:  defaultOnRejected,
-		final:     make(chan bool, 1),
+		final:     make(chan struct{}),
+		executor:  executorFrom(ctx),
+		clock:     clock,
+		logger:    loggerFrom(ctx),
+		hooks:     hooksFrom(ctx),
+	}
+	promise.self = promise
+	promise.log(LevelDebug, "create")
+	promise.hookCreate()
+	promise.traceStart(parent)
+	promise.watch()
+	return promise
+}
+
+/*
+	Reject pending promise when context is cancelled
+ */
+func (p *Promise) watch() {
+
+	if p.ctx.Done() == nil {
+		// context can't be cancelled
+		return
 	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.stopWatch = context.AfterFunc(p.ctx, p.cancel)
+}
+
+func (p *Promise) cancel() {
+
+	p.log(LevelDebug, "context is done")
+	p.finalize(Rejected, nil)
+}
+
+/*
+	Handler gets own context, that's why it can be cancelled without chain
+ */
+func withContext(p *Promise, onSuccess func(ctx context.Context, value interface{}) interface{}) func(value interface{}) interface{} {
+
+	return func(value interface{}) interface{} {
+		ctx, cancel := context.WithCancelCause(p.traceContext(p.ctx))
+		defer cancel(nil)
+
+		p.mutex.Lock()
+		p.cancelHandler = cancel
+		if p.abortCause != nil {
+			cancel(p.abortCause)
+		}
+		p.mutex.Unlock()
+
+		return onSuccess(ctx, value)
+	}
+}
+
+/*
+	Cancel context of context-aware handler, even if it isn't started
+
+	Handler can get cause by context.Cause(ctx).
+ */
+func (p *Promise) abort(cause error) {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.state != Pending {
+		return
+	}
+	p.abortCause = cause
+	if p.cancelHandler != nil {
+		p.cancelHandler(cause)
+	}
+}
+
+/*
+	Reason to stop long handler like Retry: promise is settled by Cancel,
+	chain is cancelled or promise is aborted by Timeout, nil otherwise
+ */
+func (p *Promise) stopCause() error {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	switch {
+	case p.state != Pending:
+		return p.result.err
+	case p.abortCause != nil:
+		return p.abortCause
+	case p.ctx.Err() != nil:
+		return &CancelledError{Id: p.id, Cause: p.ctx.Err()}
+	default:
+		return nil
+	}
+}
+
+/*
+	Run handlers by executor of chain
+ */
+func (p *Promise) start(oldResult *result) {
+
+	p.executor.Execute(func() { p.process(oldResult) })
 }
 
 func (p *Promise) process(oldResult *result) {
 
-	log.Printf("%v - process", p)
-	if oldResult == nil {
+	p.log(LevelDebug, "process")
+	if p.ctx.Err() != nil {
+		// chain is cancelled, handler mustn't start
+		p.cancel()
+		return
+	}
+
+	var r *result
+	switch {
+	case oldResult == nil:
 		// first promise and new promise in process line
-		p.result = resolve(p.onSuccess(nil))
-	} else {
-		// let's see result previous promise
-		switch oldResult.resultType {
-		case ERROR:
-			p.result = oldResult.copy()
-		case VALUE:
-			p.result = resolve(p.onSuccess(oldResult.value))
-		case PROMISE:
-			panic("promise result type 'PROMISE' is error!")
-		default:
-			panic("promise result type is undefined!")
-		}
+		r = p.callOnSuccess(nil)
+	case oldResult.resultType == ERROR:
+		r = p.callOnReject(oldResult.err)
+	default:
+		// result of settled promise is always value or error
+		r = p.callOnSuccess(oldResult.value)
+	}
+	if p.logEnabled(LevelDebug) {
+		p.log(LevelDebug, "promise is calculated", Field{Key: "result", Value: r})
 	}
-	log.Printf("%v - promise is calculated %v", p, p.result)
 
-	p.postProcess()
+	p.postProcess(r)
 }
 
-func (p *Promise) postProcess() {
+func (p *Promise) postProcess(r *result) {
 
-	log.Printf("%v - post process", p)
-	switch p.result.resultType {
+	p.log(LevelDebug, "post process")
+	switch r.resultType {
 	case ERROR:
-
-		p.result = resolve(p.onReject(p.result.err))
-		if p.result.resultType == ERROR {
-			p.finalize(rejected)
-			break
-		}
-		log.Printf("%v - resolve error, new result %v", p, p.result)
-		p.postProcess()
+		p.finalize(Rejected, r)
 	case PROMISE:
-		p.processNewPromise()
-	case VALUE:
-		p.finalize(success)
+		p.processNewPromise(r)
+	case THENABLE:
+		p.executor.Execute(func() { p.adoptThenable(r.thenable) })
 	default:
-		panic("promise result type is undefined!")
+		p.finalize(Success, r)
 	}
 }
 
-func (p *Promise) processNewPromise() {
-	newP := p.result.promise
-	log.Printf("%v - wait result new promise", p)
-	_, err := newP.Get()
+/*
+	Adopt state of new promise without blocking of goroutine
+
+	Like NewPromiseResolveThenableJob of JS: subscription is a job
+	and result is applied by next job.
+ */
+func (p *Promise) processNewPromise(r *result) {
 
-	if _, ok := err.(TimeoutError); ok {
-		log.Printf("%v - new promise %v fail by timeout", p, newP)
-		p.result = &result{
+	newP := r.promise
+	if p.logEnabled(LevelDebug) {
+		p.log(LevelDebug, "wait result new promise", Field{Key: "new_promise", Value: newP.id})
+	}
+	if chain := p.adopt(newP); chain != nil {
+		p.postProcess(&result{
 			resultType: ERROR,
-			err:        err,
-		}
+			err:        &CycleError{Id: p.id, Chain: chain},
+		})
 		return
 	}
+	p.hookAdopt(newP)
+	p.executor.Execute(func() {
+		newP.subscribe(func() {
+			p.executor.Execute(func() {
+				p.release()
+				if p.getState() != Pending {
+					// cancelled while waiting
+					return
+				}
+				if p.logEnabled(LevelDebug) {
+					p.log(LevelDebug, "change result", Field{Key: "result", Value: newP.result})
+				}
+				p.postProcess(newP.result.copy())
+			})
+		})
+	})
+}
+
+/*
+	Call handlers with panic recovery, panic rejects promise by PanicError
+
+	Only one handler is called for promise: onSuccess for value of parent
+	and onReject for error of parent, their errors reject promise.
+ */
+func (p *Promise) callOnSuccess(value interface{}) *result {
+
+	return p.call(p.onSuccess, func() interface{} { return p.onSuccess(value) })
+}
+
+func (p *Promise) callOnReject(err error) *result {
 
-	log.Printf("%v -  change result from %v to %v", p, p.result, newP.result)
-	p.result = newP.result.copy()
-	p.postProcess()
+	return p.call(p.onReject, func() interface{} { return p.onReject(err) })
+}
+
+func (p *Promise) call(handler interface{}, f func() interface{}) (r *result) {
+
+	p.hookHandlerStart(handler)
+	p.traceHandlerStart(handler)
+	// recoverPanic is called before, so hook and span get PanicError
+	defer func() {
+		p.hookHandlerEnd(r)
+		p.traceHandlerEnd(r)
+	}()
+	defer p.recoverPanic(&r)
+	return resolve(f())
+}
+
+func (p *Promise) recoverPanic(r **result) {
+
+	if value := recover(); value != nil {
+		if p.logEnabled(LevelWarn) {
+			p.log(LevelWarn, "handler panic", Field{Key: "panic", Value: value})
+		}
+		*r = &result{
+			resultType: ERROR,
+			err:        newPanicError(p.id, value),
+		}
+	}
 }
 
 /*
 	Using close channel for send broadcast for all process
+
+	Only first call finalize promise, next calls are ignored.
+	Promise of cancelled chain is always rejected by CancelledError.
  */
-func (p *Promise) finalize(state state) {
-	log.Printf("%v - finalize to %v", p, state)
+func (p *Promise) finalize(state State, r *result) {
+
+	p.mutex.Lock()
+	if p.state != Pending {
+		p.mutex.Unlock()
+		if p.logEnabled(LevelDebug) {
+			p.log(LevelDebug, "already finalized", Field{Key: "skipped_state", Value: state})
+		}
+		return
+	}
+	if err := p.ctx.Err(); err != nil {
+		state = Rejected
+		r = &result{
+			resultType: ERROR,
+			err:        &CancelledError{Id: p.id, Cause: err},
+		}
+	}
 	p.state = state
+	p.result = r
+	stopWatch := p.stopWatch
+	timer := p.timer
+	reactions := p.reactions
+	handled := p.handled
+	// closures of watch and timer link to promise, garbage collector can't run finalizer of cycle
+	p.stopWatch = nil
+	p.timer = nil
+	p.reactions = nil
+	p.mutex.Unlock()
+
+	p.log(LevelDebug, "finalize")
+	if stopWatch != nil {
+		stopWatch()
+	}
+	if timer != nil {
+		timer.Stop()
+	}
+	p.hookSettle(state, r.err)
+	p.traceSettle(state, r.err)
 	close(p.final)
+	for _, f := range reactions {
+		f()
+	}
+	if state == Rejected && !handled {
+		p.trackRejection()
+	}
+}
+
+func (p *Promise) getState() State {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.state
 }
 
 /*
@@ -217,17 +710,42 @@ func (p *Promise) finalize(state state) {
  */
 func (p *Promise) add(child *Promise) {
 
-	if p.state != pending {
-		log.Printf("%v - start now", child)
-		go child.process(p.result)
+	child.log(LevelDebug, "wait")
+	p.hookChildAttached(child)
+	p.subscribe(func() {
+		child.log(LevelDebug, "start")
+		child.start(p.result)
+	})
+}
+
+/*
+	Call function after settlement of current promise
+
+	Functions of pending promise are called by finalize in order of subscription,
+	so children start in the same order as they are added like reactions of JS.
+	Subscription marks promise as handled.
+ */
+func (p *Promise) subscribe(f func()) {
+
+	p.mutex.Lock()
+	reported := p.reported && !p.handled
+	p.handled = true
+	if p.state == Pending {
+		p.reactions = append(p.reactions, f)
+		p.mutex.Unlock()
 		return
 	}
-	log.Printf("%v - wait", child)
-	go func() {
-		_, ok := <-p.final
-		if !ok {
-			log.Printf("%v - freeze start", child)
-			go child.process(p.result)
-		}
-	}()
+	p.mutex.Unlock()
+	if reported {
+		p.rejectionHandled()
+	}
+	f()
+}
+
+/*
+	Like subscribe, but function is a job of executor
+ */
+func (p *Promise) react(f func()) {
+
+	p.subscribe(func() { p.executor.Execute(f) })
 }
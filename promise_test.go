@@ -1,6 +1,7 @@
 package go_promise
 
 import (
+	"context"
 	"testing"
 	"github.com/stretchr/testify/assert"
 	"fmt"
@@ -229,7 +230,7 @@ func TestTimeoutError(t *testing.T) {
 	}).GetWithTimeout(100 * time.Millisecond)
 
 	assert.Equal(t, nil, value)
-	assert.Errorf(t, err, "timeout error")
+	assert.ErrorIs(t, err, ErrTimeout)
 }
 
 func TestTimeout(t *testing.T) {
@@ -266,3 +267,187 @@ func TestResolveErrorToValueAndUseValue(t *testing.T) {
 	assert.Equal(t, testStr3+testStr5, value)
 	assert.NoError(t, err)
 }
+
+func TestThenWithContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), testStr3, testStr4))
+	defer cancel()
+
+	value, err := NewPromiseWithContext(ctx, func(ctx context.Context, d interface{}) interface{} { return testStr1 }).
+		ThenWithContext(func(c context.Context, d interface{}) interface{} {
+		assert.Equal(t, testStr4, c.Value(testStr3))
+		assert.NoError(t, c.Err())
+		return d.(string) + testStr2
+	}).Get()
+
+	assert.Equal(t, testStr1+testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestThenWithContextWithoutParentContext(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).
+		ThenWithContext(func(ctx context.Context, d interface{}) interface{} {
+		assert.NoError(t, ctx.Err())
+		return d.(string) + testStr2
+	}).Get()
+
+	assert.Equal(t, testStr1+testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestAwaitLongProcess(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} {
+		time.Sleep(2 * defaultTimeout)
+		return testStr1
+	}).Await()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestAwaitContextByCancel(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	promise := NewPromise(func(d interface{}) interface{} {
+		time.Sleep(200 * time.Millisecond)
+		return testStr1
+	})
+	value, err := promise.AwaitContext(ctx)
+
+	assert.Equal(t, nil, value)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	value, err = promise.Await()
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestDoneInSelect(t *testing.T) {
+
+	release := make(chan bool)
+	promise := NewPromise(func(d interface{}) interface{} {
+		<-release
+		return testStr1
+	})
+
+	select {
+	case <-promise.Done():
+		assert.Fail(t, "promise mustn't be settled")
+	default:
+	}
+	assert.Equal(t, Pending, promise.State())
+	assert.Equal(t, nil, promise.Value())
+	assert.NoError(t, promise.Err())
+
+	close(release)
+	select {
+	case <-promise.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "promise must be settled")
+	}
+	assert.Equal(t, Success, promise.State())
+	assert.Equal(t, testStr1, promise.Value())
+	assert.NoError(t, promise.Err())
+}
+
+func TestStateOfRejectedPromise(t *testing.T) {
+
+	promise := NewPromise(func(d interface{}) interface{} { return fmt.Errorf(testErr1) })
+	<-promise.Done()
+
+	assert.Equal(t, Rejected, promise.State())
+	assert.Equal(t, nil, promise.Value())
+	assert.EqualError(t, promise.Err(), testErr1)
+	assert.Equal(t, "rejected", promise.State().String())
+}
+
+func TestFinallyByData(t *testing.T) {
+
+	called := 0
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).
+		Finally(func() { called++ }).
+		Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, called)
+}
+
+func TestFinallyByError(t *testing.T) {
+
+	called := 0
+	value, err := NewPromise(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).
+		Finally(func() { called++ }).
+		Get()
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr1)
+	assert.Equal(t, 1, called)
+}
+
+func TestFinallyByPanic(t *testing.T) {
+
+	called := 0
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).
+		Finally(func() {
+		called++
+		panic(testStr2)
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	assert.IsType(t, &PanicError{}, err)
+	assert.Equal(t, 1, called)
+}
+
+func TestFinallyAndThen(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).
+		Finally(func() {}).
+		Then(func(d interface{}) interface{} { return d.(string) + testStr2 }).
+		Get()
+
+	assert.Equal(t, testStr1+testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestFinallyAsyncByData(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).
+		FinallyAsync(func() *Promise { return Resolve(testStr2) }).
+		Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestFinallyAsyncByRejectedPromise(t *testing.T) {
+
+	called := 0
+	value, err := NewPromise(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).
+		FinallyAsync(func() *Promise {
+		called++
+		return Reject(fmt.Errorf(testErr2))
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr2)
+	assert.Equal(t, 1, called)
+}
+
+func TestFinallyAsyncRejectsFulfilledPromise(t *testing.T) {
+
+	called := 0
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).
+		FinallyAsync(func() *Promise {
+		called++
+		return Reject(fmt.Errorf(testErr2))
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr2)
+	assert.Equal(t, 1, called)
+}
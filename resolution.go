@@ -0,0 +1,63 @@
+package go_promise
+
+import "sync"
+
+/*
+	Guard of Promise.adopting of all promises, cycle can contain promises of different chains
+ */
+var adoption sync.Mutex
+
+/*
+	Start waiting of new promise
+
+	Returns chain of ids if new promise is current promise or waits it.
+ */
+func (p *Promise) adopt(newP *Promise) []string {
+
+	adoption.Lock()
+	defer adoption.Unlock()
+	chain := []string{p.id}
+	for next := newP; next != nil; next = next.adopting {
+		chain = append(chain, next.id)
+		if next == p {
+			return chain
+		}
+	}
+	p.adopting = newP
+	return nil
+}
+
+func (p *Promise) release() {
+
+	adoption.Lock()
+	defer adoption.Unlock()
+	p.adopting = nil
+}
+
+/*
+	Call Then of thenable, first call of resolve or reject settles promise
+ */
+func (p *Promise) adoptThenable(thenable Thenable) {
+
+	var once sync.Once
+	resolve := func(value interface{}) {
+		once.Do(func() { p.settleWith(value) })
+	}
+	reject := func(err error) {
+		if err == nil {
+			err = ErrNilReason
+		}
+		once.Do(func() { p.postProcess(&result{resultType: ERROR, err: err}) })
+	}
+	defer func() {
+		if value := recover(); value != nil {
+			if p.logEnabled(LevelWarn) {
+				p.log(LevelWarn, "thenable panic", Field{Key: "panic", Value: value})
+			}
+			reject(newPanicError(p.id, value))
+		}
+	}()
+
+	p.log(LevelDebug, "adopt thenable")
+	thenable.Then(resolve, reject)
+}
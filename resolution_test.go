@@ -0,0 +1,143 @@
+package go_promise
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testThenable struct {
+	then func(resolve func(value interface{}), reject func(err error))
+}
+
+func (t testThenable) Then(resolve func(value interface{}), reject func(err error)) {
+	t.then(resolve, reject)
+}
+
+func TestResolveToItself(t *testing.T) {
+
+	var promise *Promise
+	release := make(chan struct{})
+	promise = NewPromise(func(d interface{}) interface{} {
+		<-release
+		return promise
+	})
+	close(release)
+	_, err := promise.Await()
+
+	var cycle *CycleError
+	assert.True(t, errors.As(err, &cycle))
+	assert.ErrorIs(t, err, ErrChainingCycle)
+	assert.Equal(t, []string{promise.id, promise.id}, cycle.Chain)
+}
+
+func TestResolveToCycle(t *testing.T) {
+
+	var first, second *Promise
+	release := make(chan struct{})
+	first = NewPromise(func(d interface{}) interface{} {
+		<-release
+		return second
+	})
+	second = NewPromise(func(d interface{}) interface{} {
+		<-release
+		return first
+	})
+	close(release)
+
+	_, err1 := first.Await()
+	_, err2 := second.Await()
+	assert.ErrorIs(t, err1, ErrChainingCycle)
+	assert.ErrorIs(t, err2, ErrChainingCycle)
+}
+
+func TestResolveToPromiseValue(t *testing.T) {
+
+	inner := NewPromise(F(testStr1))
+	// copy of running promise is data race
+	inner.Await()
+	result := resolve(*inner)
+
+	assert.Same(t, inner, result.promise)
+
+	value, err := NewPromise(func(d interface{}) interface{} { return *inner }).Await()
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestResolveToInvalidPromise(t *testing.T) {
+
+	_, err := NewPromise(func(d interface{}) interface{} { return (*Promise)(nil) }).Await()
+	assert.ErrorIs(t, err, ErrInvalidPromise)
+
+	_, err = NewPromise(func(d interface{}) interface{} { return Promise{} }).Await()
+	assert.ErrorIs(t, err, ErrInvalidPromise)
+}
+
+func TestResolveThenable(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} {
+		return testThenable{then: func(resolve func(value interface{}), reject func(err error)) {
+			go resolve(testStr1)
+		}}
+	}).Then(func(d interface{}) interface{} { return d.(string) + testStr2 }).Await()
+
+	assert.Equal(t, testStr1+testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestResolveThenableOnlyFirstCall(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} {
+		return testThenable{then: func(resolve func(value interface{}), reject func(err error)) {
+			reject(fmt.Errorf(testErr1))
+			resolve(testStr1)
+			panic(testStr2)
+		}}
+	}).Await()
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr1)
+}
+
+func TestResolveThenablePanic(t *testing.T) {
+
+	_, err := NewPromise(func(d interface{}) interface{} {
+		return testThenable{then: func(resolve func(value interface{}), reject func(err error)) {
+			panic(testStr1)
+		}}
+	}).Await()
+
+	var panicErr *PanicError
+	assert.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, testStr1, panicErr.Value)
+}
+
+func TestResolveNestedFlatten(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} {
+		return testThenable{then: func(resolve func(value interface{}), reject func(err error)) {
+			resolve(NewPromise(func(d interface{}) interface{} {
+				return testThenable{then: func(resolve func(value interface{}), reject func(err error)) {
+					resolve(Resolve(testStr1))
+				}}
+			}))
+		}}
+	}).Await()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestResolveThenableRejectByNil(t *testing.T) {
+
+	_, err := NewPromise(func(d interface{}) interface{} {
+		return testThenable{then: func(resolve func(value interface{}), reject func(err error)) {
+			reject(nil)
+		}}
+	}).Await()
+
+	assert.ErrorIs(t, err, ErrNilReason)
+}
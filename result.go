@@ -5,6 +5,7 @@ type result struct {
 	value      interface{}
 	err        error
 	promise    *Promise
+	thenable   Thenable
 	resultType resultType
 }
 
@@ -25,8 +26,21 @@ const (
 	ERROR
 	VALUE
 	PROMISE
+	THENABLE
 )
 
+/*
+	Value which can be adopted by promise like thenable of JS
+
+	Then must call resolve or reject once, next calls are ignored.
+	Value of resolve is handled like result of handler, so nested
+	promises and thenables are flattened. Panic of Then rejects promise,
+	if resolve or reject isn't called before.
+ */
+type Thenable interface {
+	Then(resolve func(value interface{}), reject func(err error))
+}
+
 /*
 	This method simplifies API
 
@@ -51,15 +65,15 @@ func resolve(data interface{}) *result {
 			err:        data.(error),
 		}
 	case *Promise:
-		return &result{
-			resultType: PROMISE,
-			promise:    data.(*Promise),
-		}
+		return resolvePromise(data.(*Promise))
 	case Promise:
+		// copy of promise has link to original promise, its state isn't copied
 		promise := data.(Promise)
+		return resolvePromise(promise.self)
+	case Thenable:
 		return &result{
-			resultType: PROMISE,
-			promise:    &promise,
+			resultType: THENABLE,
+			thenable:   data.(Thenable),
 		}
 	default:
 		return &result{
@@ -68,3 +82,18 @@ func resolve(data interface{}) *result {
 		}
 	}
 }
+
+func resolvePromise(promise *Promise) *result {
+
+	if promise == nil {
+		// nil pointer or Promise{} without constructor
+		return &result{
+			resultType: ERROR,
+			err:        ErrInvalidPromise,
+		}
+	}
+	return &result{
+		resultType: PROMISE,
+		promise:    promise,
+	}
+}
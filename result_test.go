@@ -26,7 +26,10 @@ func TestResolveResultByValue(t *testing.T) {
 
 func TestResolveResultByPromise(t *testing.T) {
 
-	result := resolve(*NewPromise(F(testStr1)))
+	promise := NewPromise(F(testStr1))
+	// copy of running promise is data race
+	promise.Await()
+	result := resolve(*promise)
 	assert.Equal(t, PROMISE, result.resultType)
 	assert.NoError(t, result.err)
 	assert.True(t, nil != result.promise)
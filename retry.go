@@ -0,0 +1,216 @@
+package go_promise
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+/*
+	Backoff calculates delay before next attempt
+
+	attempt - number of failed attempt, starts from 1
+	previous - previous delay, 0 before first delay
+ */
+type Backoff interface {
+	Delay(attempt int, previous time.Duration) time.Duration
+}
+
+/*
+	Same delay before every attempt
+ */
+func ConstantBackoff(delay time.Duration) Backoff {
+
+	return constantBackoff{delay: delay}
+}
+
+/*
+	Delay is initial * multiplier^(attempt-1), but not more than max
+
+	multiplier <= 1 is replaced by 2, max <= 0 means without limit.
+ */
+func ExponentialBackoff(initial, max time.Duration, multiplier float64) Backoff {
+
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+	return exponentialBackoff{initial: initial, max: max, multiplier: multiplier}
+}
+
+/*
+	Random delay between base and previous * 3, but not more than max
+
+	It's "decorrelated jitter" from AWS Architecture Blog,
+	max <= 0 means without limit.
+ */
+func DecorrelatedJitterBackoff(base, max time.Duration) Backoff {
+
+	return decorrelatedJitterBackoff{base: base, max: max}
+}
+
+type constantBackoff struct {
+	delay time.Duration
+}
+
+func (b constantBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+	return b.delay
+}
+
+type exponentialBackoff struct {
+	initial    time.Duration
+	max        time.Duration
+	multiplier float64
+}
+
+func (b exponentialBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+
+	delay := float64(b.initial) * math.Pow(b.multiplier, float64(attempt-1))
+	if b.max > 0 && delay > float64(b.max) {
+		return b.max
+	}
+	return time.Duration(delay)
+}
+
+type decorrelatedJitterBackoff struct {
+	base time.Duration
+	max  time.Duration
+}
+
+func (b decorrelatedJitterBackoff) Delay(attempt int, previous time.Duration) time.Duration {
+
+	if previous < b.base {
+		previous = b.base
+	}
+	delay := b.base
+	if upper := previous * 3; upper > b.base {
+		delay += time.Duration(rand.Int63n(int64(upper - b.base)))
+	}
+	if b.max > 0 && delay > b.max {
+		return b.max
+	}
+	return delay
+}
+
+/*
+	Rules of Retry
+
+	MaxAttempts - count of attempts, 0 means without limit
+	MaxElapsed - attempt isn't started if its delay ends after MaxElapsed
+		from start of first attempt, 0 means without limit
+	Backoff - delay before next attempt, nil means without delay
+	Retryable - check error of attempt, nil means all errors are retryable
+ */
+type RetryPolicy struct {
+	MaxAttempts int
+	MaxElapsed  time.Duration
+	Backoff     Backoff
+	Retryable   func(err error) bool
+}
+
+/*
+	Call function until success or end of policy
+
+	Function can return value, error or promise like handler of Then,
+	attempt starts from 1. Promise is fulfilled by value of first success attempt
+	or rejected by RetryError with errors of all attempts.
+	Attempts and waiting of backoff are stopped by Cancel and Timeout.
+
+	Use:
+		Retry(RetryPolicy{MaxAttempts: 5, Backoff: ExponentialBackoff(...)},
+			func(attempt int) interface{} { return request() })
+ */
+func Retry(policy RetryPolicy, function func(attempt int) interface{}) *Promise {
+
+	return retry(context.Background(), policy, func(ctx context.Context, attempt int) interface{} {
+		return function(attempt)
+	})
+}
+
+/*
+	Like Retry, but with context of chain
+
+	Function gets context which is cancelled by cancellation of ctx, Cancel or Timeout.
+	Cancellation of ctx rejects promise by CancelledError.
+
+	Use:
+		RetryWithContext(r.Context(), policy, func(ctx context.Context, attempt int) interface{} {
+			return request(ctx)
+		})
+ */
+func RetryWithContext(ctx context.Context, policy RetryPolicy,
+	function func(ctx context.Context, attempt int) interface{}) *Promise {
+
+	return retry(ctx, policy, function)
+}
+
+/*
+	Attempts are started by reaction to previous attempt and timer of clock,
+	so goroutines aren't blocked by waiting
+ */
+func retry(ctx context.Context, policy RetryPolicy, function func(ctx context.Context, attempt int) interface{}) *Promise {
+
+	deferred := NewDeferredWithContext(ctx)
+	promise := deferred.Promise
+	// like context of handler, Cancel and Timeout cancel it by abort
+	ctx, cancel := context.WithCancelCause(ctx)
+	promise.mutex.Lock()
+	promise.cancelHandler = cancel
+	promise.mutex.Unlock()
+
+	start := promise.clock.Now()
+	errs := make([]error, 0)
+	delay := time.Duration(0)
+
+	var run func(attempt int)
+	run = func(attempt int) {
+
+		if err := promise.stopCause(); err != nil {
+			deferred.Reject(err)
+			cancel(nil)
+			return
+		}
+		child := newContextPromise(ctx, nil)
+		child.onSuccess = withContext(child, func(ctx context.Context, d interface{}) interface{} {
+			return function(ctx, attempt)
+		})
+		child.start(nil)
+
+		child.react(func() {
+			err := child.result.err
+			if err == nil {
+				deferred.Resolve(child.result.value)
+				cancel(nil)
+				return
+			}
+			errs = append(errs, err)
+			if promise.logEnabled(LevelInfo) {
+				promise.log(LevelInfo, "attempt is failed", Field{Key: "attempt", Value: attempt}, Field{Key: "error", Value: err})
+			}
+
+			if policy.Backoff != nil {
+				delay = policy.Backoff.Delay(attempt, delay)
+			}
+			if policy.Retryable != nil && !policy.Retryable(err) ||
+				policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts ||
+				policy.MaxElapsed > 0 && promise.clock.Now().Sub(start)+delay > policy.MaxElapsed {
+				deferred.Reject(&RetryError{Id: promise.id, Errors: errs})
+				cancel(nil)
+				return
+			}
+
+			// backoff is stopped by cancellation of context, next attempt is started once
+			var once sync.Once
+			next := func() { once.Do(func() { run(attempt + 1) }) }
+			timer := promise.clock.AfterFunc(delay, next)
+			context.AfterFunc(ctx, func() {
+				timer.Stop()
+				next()
+			})
+		})
+	}
+
+	run(1)
+	return promise
+}
@@ -0,0 +1,215 @@
+package go_promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryUntilSuccess(t *testing.T) {
+
+	attempts := 0
+	value, err := Retry(RetryPolicy{MaxAttempts: 5}, func(attempt int) interface{} {
+		attempts = attempt
+		if attempt < 3 {
+			return fmt.Errorf(testErr1)
+		}
+		return testStr1
+	}).Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+
+	value, err := Retry(RetryPolicy{MaxAttempts: 3}, func(attempt int) interface{} {
+		return fmt.Errorf("ups %d", attempt)
+	}).Get()
+
+	assert.Equal(t, nil, value)
+	var retryErr *RetryError
+	assert.True(t, errors.As(err, &retryErr))
+	assert.Len(t, retryErr.Errors, 3)
+	assert.EqualError(t, retryErr.Errors[0], "ups 1")
+	assert.EqualError(t, retryErr.Errors[2], "ups 3")
+}
+
+func TestRetryNotRetryableError(t *testing.T) {
+
+	fatal := fmt.Errorf(testErr2)
+	attempts := 0
+	_, err := Retry(RetryPolicy{
+		MaxAttempts: 5,
+		Retryable:   func(err error) bool { return err != fatal },
+	}, func(attempt int) interface{} {
+		attempts = attempt
+		if attempt == 2 {
+			return fatal
+		}
+		return fmt.Errorf(testErr1)
+	}).Get()
+
+	assert.ErrorIs(t, err, fatal)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRetryMaxElapsed(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	attempts := 0
+	promise := Retry(RetryPolicy{
+		MaxElapsed: 50 * time.Millisecond,
+		Backoff:    ConstantBackoff(20 * time.Millisecond),
+	}, func(attempt int) interface{} {
+		attempts = attempt
+		return fmt.Errorf(testErr1)
+	})
+
+	for i := 0; i < 2; i++ {
+		clock.BlockUntil(1)
+		clock.Advance(20 * time.Millisecond)
+	}
+	_, err := promise.Await()
+
+	assert.Error(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetryStopsAfterCancel(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	var attempts int32
+	promise := Retry(RetryPolicy{Backoff: ConstantBackoff(10 * time.Millisecond)}, func(attempt int) interface{} {
+		atomic.StoreInt32(&attempts, int32(attempt))
+		return fmt.Errorf(testErr1)
+	})
+	clock.BlockUntil(1)
+	promise.Cancel()
+
+	// waiting of backoff is stopped by settlement
+	for clock.Timers() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	clock.Advance(time.Second)
+	_, err := promise.Await()
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryStopsAfterTimeout(t *testing.T) {
+
+	clock := NewFakeClock(time.Now())
+	SetClock(clock)
+	defer SetClock(nil)
+
+	var attempts int32
+	promise := Retry(RetryPolicy{Backoff: ConstantBackoff(10 * time.Millisecond)}, func(attempt int) interface{} {
+		atomic.StoreInt32(&attempts, int32(attempt))
+		return fmt.Errorf(testErr1)
+	})
+	timeout := promise.Timeout(15 * time.Millisecond)
+
+	clock.BlockUntil(2)
+	clock.Advance(10 * time.Millisecond)
+	clock.BlockUntil(2)
+	clock.Advance(5 * time.Millisecond)
+	_, err := timeout.Await()
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	// waiting of backoff is stopped by timeout
+	_, err = promise.Await()
+	assert.ErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+	assert.Equal(t, 0, clock.Timers())
+}
+
+func TestRetryWithContext(t *testing.T) {
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var attempts int32
+
+	promise := RetryWithContext(ctx, RetryPolicy{}, func(ctx context.Context, attempt int) interface{} {
+		if atomic.AddInt32(&attempts, 1) == 2 {
+			cancel()
+			<-ctx.Done()
+		}
+		return fmt.Errorf(testErr1)
+	})
+	_, err := promise.Await()
+	time.Sleep(20 * time.Millisecond)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&attempts))
+}
+
+func TestRetryWithPoolExecutor(t *testing.T) {
+
+	pool := NewPoolExecutor(1)
+	defer pool.Close()
+	SetExecutor(pool)
+	defer SetExecutor(nil)
+
+	value, err := Retry(RetryPolicy{MaxAttempts: 3}, func(attempt int) interface{} {
+		if attempt < 3 {
+			return fmt.Errorf(testErr1)
+		}
+		return testStr1
+	}).GetWithTimeout(time.Second)
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestRetryPanicAndPromise(t *testing.T) {
+
+	value, err := Retry(RetryPolicy{MaxAttempts: 3}, func(attempt int) interface{} {
+		if attempt == 1 {
+			panic(testStr1)
+		}
+		return Resolve(testStr2)
+	}).Get()
+
+	assert.Equal(t, testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestExponentialBackoff(t *testing.T) {
+
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond, 2)
+
+	assert.Equal(t, 10*time.Millisecond, backoff.Delay(1, 0))
+	assert.Equal(t, 20*time.Millisecond, backoff.Delay(2, 0))
+	assert.Equal(t, 40*time.Millisecond, backoff.Delay(3, 0))
+	assert.Equal(t, 50*time.Millisecond, backoff.Delay(4, 0))
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+
+	backoff := DecorrelatedJitterBackoff(10*time.Millisecond, time.Second)
+
+	delay := time.Duration(0)
+	for attempt := 1; attempt < 100; attempt++ {
+		previous := delay
+		if previous < 10*time.Millisecond {
+			previous = 10 * time.Millisecond
+		}
+		delay = backoff.Delay(attempt, delay)
+		assert.True(t, delay >= 10*time.Millisecond)
+		assert.True(t, delay <= time.Second)
+		assert.True(t, delay <= 3*previous)
+	}
+}
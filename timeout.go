@@ -0,0 +1,42 @@
+package go_promise
+
+import "time"
+
+/*
+	Add new promise which is settled by current promise or rejected
+	by TimeoutError after timeout
+
+	Rejection is transferred to child promises like other errors.
+	Context-aware handler of current promise gets cancelled context
+	with TimeoutError cause.
+ */
+func (p *Promise) Timeout(timeout time.Duration) *Promise {
+
+	promise := newPromise(p)
+	p.hookChildAttached(promise)
+	timer := p.clock.AfterFunc(timeout, func() {
+		err := &TimeoutError{Id: p.id, Timeout: timeout, rejectedId: promise.id}
+		if promise.logEnabled(LevelInfo) {
+			promise.log(LevelInfo, "timeout", Field{Key: "timeout", Value: timeout})
+		}
+		promise.finalize(Rejected, &result{
+			resultType: ERROR,
+			err:        err,
+		})
+		p.abort(err)
+	})
+
+	p.subscribe(func() {
+		timer.Stop()
+		promise.finalize(p.getState(), p.result.copy())
+	})
+	return promise
+}
+
+/*
+	Like Timeout, but with deadline
+ */
+func (p *Promise) WithDeadline(deadline time.Time) *Promise {
+
+	return p.Timeout(deadline.Sub(p.clock.Now()))
+}
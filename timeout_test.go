@@ -0,0 +1,111 @@
+package go_promise
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutBySettlement(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} { return testStr1 }).
+		Timeout(100 * time.Millisecond).
+		Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestTimeoutByRejection(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} { return fmt.Errorf(testErr1) }).
+		Timeout(100 * time.Millisecond).
+		Get()
+
+	assert.Equal(t, nil, value)
+	assert.EqualError(t, err, testErr1)
+}
+
+func TestTimeoutRejectsChild(t *testing.T) {
+
+	parent := NewPromise(func(d interface{}) interface{} {
+		time.Sleep(100 * time.Millisecond)
+		return testStr1
+	})
+	thenCalled := false
+	value, err := parent.
+		Timeout(10 * time.Millisecond).
+		Then(func(d interface{}) interface{} {
+		thenCalled = true
+		return d
+	}).
+		Catch(func(err error) interface{} {
+		if errors.Is(err, ErrTimeout) {
+			return testStr2
+		}
+		return err
+	}).Get()
+
+	assert.Equal(t, testStr2, value)
+	assert.NoError(t, err)
+	assert.False(t, thenCalled)
+
+	// original promise isn't changed
+	value, err = parent.Get()
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestTimeoutCancelsContextAwareHandler(t *testing.T) {
+
+	cause := make(chan error, 1)
+	parent := NewPromiseWithContext(context.Background(), func(ctx context.Context, d interface{}) interface{} {
+		<-ctx.Done()
+		cause <- context.Cause(ctx)
+		return ctx.Err()
+	})
+
+	_, err := parent.Timeout(10 * time.Millisecond).Get()
+	var timeoutErr *TimeoutError
+	assert.True(t, errors.As(err, &timeoutErr))
+	assert.Equal(t, parent.id, timeoutErr.Id)
+
+	assert.ErrorIs(t, <-cause, ErrTimeout)
+	_, err = parent.Get()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTimeoutCancelsNotStartedHandler(t *testing.T) {
+
+	parent := NewPromise(func(d interface{}) interface{} {
+		time.Sleep(50 * time.Millisecond)
+		return testStr1
+	})
+	child := parent.ThenWithContext(func(ctx context.Context, d interface{}) interface{} {
+		if ctx.Err() != nil {
+			return context.Cause(ctx)
+		}
+		return d
+	})
+
+	_, err := child.Timeout(10 * time.Millisecond).Get()
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	_, err = child.Get()
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestWithDeadline(t *testing.T) {
+
+	value, err := NewPromise(func(d interface{}) interface{} {
+		time.Sleep(100 * time.Millisecond)
+		return testStr1
+	}).WithDeadline(time.Now().Add(10 * time.Millisecond)).Get()
+
+	assert.Equal(t, nil, value)
+	assert.ErrorIs(t, err, ErrTimeout)
+}
@@ -0,0 +1,289 @@
+package go_promise
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"time"
+)
+
+/*
+	Source of spans, one span per promise
+
+	parent is span of parent promise, it's nil for parent promise,
+	then tracer can take parent span from ctx.
+	Span is created with name "promise", name is changed by SetName
+	when handler is started.
+	Use otelpromise package for OpenTelemetry.
+ */
+type Tracer interface {
+	Start(ctx context.Context, parent Span, name string) Span
+}
+
+/*
+	Span of promise
+
+	Attributes: promise.id, promise.parent_id, promise.state and promise.panic.
+	RecordError is called for error of handler and for rejection of promise.
+	End is called once when promise is settled.
+ */
+type Span interface {
+	SetName(name string)
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+/*
+	Span which can be put to context
+
+	Context-aware handlers get context with span of their promise,
+	so spans of their work are children of promise span.
+ */
+type ContextSpan interface {
+	Span
+	ContextWithSpan(ctx context.Context) context.Context
+}
+
+const defaultSpanName = "promise"
+
+type tracerKey struct{}
+
+var tracer = struct {
+	sync.RWMutex
+	value Tracer
+}{}
+
+/*
+	Set package-wide tracer, it's used by chains without own tracer
+
+	nil disables tracing.
+ */
+func SetTracer(t Tracer) {
+
+	tracer.Lock()
+	defer tracer.Unlock()
+	tracer.value = t
+}
+
+/*
+	Tracer for chain created by NewPromiseWithContext
+ */
+func WithTracer(ctx context.Context, t Tracer) context.Context {
+
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+func tracerFrom(ctx context.Context) Tracer {
+
+	if t, ok := ctx.Value(tracerKey{}).(Tracer); ok && t != nil {
+		return t
+	}
+	tracer.RLock()
+	defer tracer.RUnlock()
+	return tracer.value
+}
+
+func (p *Promise) traceStart(parent *Promise) {
+
+	t := tracerFrom(p.ctx)
+	if t == nil {
+		return
+	}
+	var parentSpan Span
+	if parent != nil {
+		parentSpan = parent.span
+	}
+	p.span = t.Start(p.ctx, parentSpan, defaultSpanName)
+	p.span.SetAttribute("promise.id", p.id)
+	p.span.SetAttribute("promise.parent_id", p.parentId)
+}
+
+/*
+	Name of span is label of promise or name of first handler
+ */
+func (p *Promise) traceHandlerStart(handler interface{}) {
+
+	if p.span == nil {
+		return
+	}
+	p.mutex.Lock()
+	named := p.spanNamed
+	p.spanNamed = true
+	p.mutex.Unlock()
+	if !named {
+		p.span.SetName(p.handlerLabel(handler))
+	}
+}
+
+func (p *Promise) traceHandlerEnd(r *result) {
+
+	if p.span == nil || r.err == nil {
+		return
+	}
+	// only one handler is called for promise, settlement doesn't record its error again
+	p.mutex.Lock()
+	p.spanErr = r.err
+	p.mutex.Unlock()
+	if _, ok := r.err.(*PanicError); ok {
+		p.span.SetAttribute("promise.panic", true)
+	}
+	p.span.RecordError(r.err)
+}
+
+func (p *Promise) traceSettle(state State, err error) {
+
+	if p.span == nil {
+		return
+	}
+	p.mutex.Lock()
+	recorded := p.spanErr
+	p.mutex.Unlock()
+	// error of handler is already recorded
+	if err != nil && !sameError(err, recorded) {
+		p.span.RecordError(err)
+	}
+	p.span.SetAttribute("promise.state", state.String())
+	p.span.End()
+}
+
+/*
+	Context of context-aware handler
+ */
+func (p *Promise) traceContext(ctx context.Context) context.Context {
+
+	if span, ok := p.span.(ContextSpan); ok {
+		return span.ContextWithSpan(ctx)
+	}
+	return ctx
+}
+
+func sameError(a, b error) bool {
+
+	if a == nil || b == nil {
+		return false
+	}
+	// errors with slices and maps can't be compared by ==
+	return reflect.TypeOf(a) == reflect.TypeOf(b) && reflect.TypeOf(a).Comparable() && a == b
+}
+
+/*
+	Finished or running span of InMemoryExporter
+
+	SpanId and ParentSpanId are numbers of spans in exporter, ParentSpanId is 0 for root span.
+ */
+type SpanData struct {
+	SpanId       int
+	ParentSpanId int
+	Name         string
+	Attributes   map[string]interface{}
+	Errors       []error
+	Start        time.Time
+	End          time.Time
+	Ended        bool
+}
+
+/*
+	Tracer which keeps spans in memory, it's for tests
+
+	Use:
+		exporter := NewInMemoryExporter()
+		promise := NewPromiseWithContext(WithTracer(ctx, exporter), ...)
+		promise.Await()
+		spans := exporter.Spans()
+ */
+type InMemoryExporter struct {
+	mutex sync.Mutex
+	spans []*memorySpan
+}
+
+type memorySpan struct {
+	exporter *InMemoryExporter
+	data     SpanData
+}
+
+func NewInMemoryExporter() *InMemoryExporter {
+
+	return &InMemoryExporter{}
+}
+
+func (e *InMemoryExporter) Start(ctx context.Context, parent Span, name string) Span {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	span := &memorySpan{
+		exporter: e,
+		data: SpanData{
+			SpanId:     len(e.spans) + 1,
+			Name:       name,
+			Attributes: map[string]interface{}{},
+			Start:      time.Now(),
+		},
+	}
+	if p, ok := parent.(*memorySpan); ok && p.exporter == e {
+		span.data.ParentSpanId = p.data.SpanId
+	}
+	e.spans = append(e.spans, span)
+	return span
+}
+
+/*
+	Copy of all spans in order of start
+ */
+func (e *InMemoryExporter) Spans() []SpanData {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	spans := make([]SpanData, len(e.spans), len(e.spans))
+	for i, span := range e.spans {
+		spans[i] = span.data
+		spans[i].Attributes = make(map[string]interface{}, len(span.data.Attributes))
+		for key, value := range span.data.Attributes {
+			spans[i].Attributes[key] = value
+		}
+		spans[i].Errors = append([]error(nil), span.data.Errors...)
+	}
+	return spans
+}
+
+/*
+	Remove all spans
+ */
+func (e *InMemoryExporter) Reset() {
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.spans = nil
+}
+
+func (s *memorySpan) SetName(name string) {
+
+	s.exporter.mutex.Lock()
+	defer s.exporter.mutex.Unlock()
+	s.data.Name = name
+}
+
+func (s *memorySpan) SetAttribute(key string, value interface{}) {
+
+	s.exporter.mutex.Lock()
+	defer s.exporter.mutex.Unlock()
+	s.data.Attributes[key] = value
+}
+
+func (s *memorySpan) RecordError(err error) {
+
+	s.exporter.mutex.Lock()
+	defer s.exporter.mutex.Unlock()
+	s.data.Errors = append(s.data.Errors, err)
+}
+
+func (s *memorySpan) End() {
+
+	s.exporter.mutex.Lock()
+	defer s.exporter.mutex.Unlock()
+	if s.data.Ended {
+		return
+	}
+	s.data.Ended = true
+	s.data.End = time.Now()
+}
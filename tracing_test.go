@@ -0,0 +1,155 @@
+package go_promise
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testHandler(value interface{}) interface{} {
+	return value
+}
+
+func spanById(spans []SpanData, id string) SpanData {
+
+	for _, span := range spans {
+		if span.Attributes["promise.id"] == id {
+			return span
+		}
+	}
+	return SpanData{}
+}
+
+func TestTracingOfChain(t *testing.T) {
+
+	exporter := NewInMemoryExporter()
+	ctx := WithTracer(context.Background(), exporter)
+
+	parent := NewPromiseWithContext(ctx, func(ctx context.Context, value interface{}) interface{} {
+		return testStr1
+	}).Label("load")
+	child := parent.Then(func(d interface{}) interface{} { return d }).Label("save")
+	_, err := child.Await()
+	assert.NoError(t, err)
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 2)
+	parentSpan := spanById(spans, parent.id)
+	childSpan := spanById(spans, child.id)
+	assert.Equal(t, 0, parentSpan.ParentSpanId)
+	assert.Equal(t, parentSpan.SpanId, childSpan.ParentSpanId)
+	assert.Equal(t, parent.id, childSpan.Attributes["promise.parent_id"])
+	assert.Equal(t, "save", childSpan.Name)
+	assert.Equal(t, "success", childSpan.Attributes["promise.state"])
+	assert.True(t, parentSpan.Ended)
+	assert.True(t, childSpan.Ended)
+	assert.Empty(t, childSpan.Errors)
+}
+
+func TestTracingSpanNameOfHandler(t *testing.T) {
+
+	exporter := NewInMemoryExporter()
+	ctx := WithTracer(context.Background(), exporter)
+
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, value interface{}) interface{} {
+		return testStr1
+	}).Then(testHandler)
+	promise.Await()
+
+	assert.Equal(t, "github.com/danevge/go-promise.testHandler", spanById(exporter.Spans(), promise.id).Name)
+}
+
+func TestTracingErrors(t *testing.T) {
+
+	exporter := NewInMemoryExporter()
+	ctx := WithTracer(context.Background(), exporter)
+
+	parent := NewPromiseWithContext(ctx, func(ctx context.Context, value interface{}) interface{} {
+		return fmt.Errorf(testErr1)
+	})
+	child := parent.Then(func(d interface{}) interface{} { return d })
+	child.Await()
+
+	spans := exporter.Spans()
+	parentSpan := spanById(spans, parent.id)
+	childSpan := spanById(spans, child.id)
+	// error of handler is recorded once
+	assert.Len(t, parentSpan.Errors, 1)
+	assert.EqualError(t, parentSpan.Errors[0], testErr1)
+	assert.Equal(t, "rejected", parentSpan.Attributes["promise.state"])
+	assert.Len(t, childSpan.Errors, 1)
+	assert.Equal(t, "rejected", childSpan.Attributes["promise.state"])
+}
+
+func TestTracingPanic(t *testing.T) {
+
+	exporter := NewInMemoryExporter()
+	ctx := WithTracer(context.Background(), exporter)
+
+	promise := NewPromiseWithContext(ctx, func(ctx context.Context, value interface{}) interface{} {
+		panic(testErr1)
+	})
+	promise.Await()
+
+	span := spanById(exporter.Spans(), promise.id)
+	assert.Equal(t, true, span.Attributes["promise.panic"])
+	assert.Len(t, span.Errors, 1)
+	assert.IsType(t, &PanicError{}, span.Errors[0])
+}
+
+func TestTracingContextSpan(t *testing.T) {
+
+	tracer := &contextTracer{InMemoryExporter: NewInMemoryExporter()}
+	ctx := WithTracer(context.Background(), tracer)
+
+	value, err := NewPromiseWithContext(ctx, func(ctx context.Context, value interface{}) interface{} {
+		return ctx.Value(contextSpanKey{}) != nil
+	}).Await()
+
+	assert.Equal(t, true, value)
+	assert.NoError(t, err)
+}
+
+func TestTracingWithoutTracer(t *testing.T) {
+
+	promise := NewPromise(testHandler)
+	promise.Await()
+
+	assert.Nil(t, promise.span)
+}
+
+func TestTracingGlobal(t *testing.T) {
+
+	exporter := NewInMemoryExporter()
+	SetTracer(exporter)
+	defer SetTracer(nil)
+
+	NewPromise(testHandler).Label("global").Await()
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 1)
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
+
+type contextSpanKey struct{}
+
+type contextTracer struct {
+	*InMemoryExporter
+}
+
+type contextSpan struct {
+	Span
+}
+
+func (t *contextTracer) Start(ctx context.Context, parent Span, name string) Span {
+
+	return contextSpan{Span: t.InMemoryExporter.Start(ctx, parent, name)}
+}
+
+func (s contextSpan) ContextWithSpan(ctx context.Context) context.Context {
+
+	return context.WithValue(ctx, contextSpanKey{}, s)
+}
@@ -0,0 +1,203 @@
+/*
+	Type-safe wrapper over go_promise.Promise
+
+	Handlers receive and return concrete types, so chains don't need
+	type assertions like d.(string). All settle and finalize work is done
+	by the untyped promise inside, so both APIs can be mixed in one chain.
+
+	T must not be an error type or *go_promise.Promise: such values are
+	treated by the untyped promise as rejection or as a new promise.
+ */
+package typed
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	promise "github.com/danevge/go-promise"
+)
+
+type Promise[T any] struct {
+	p *promise.Promise
+}
+
+/*
+	Create parent promise
+
+	JS example: new Promise(resolve => resolve(value));
+ */
+func NewPromise[T any](onSuccess func() (T, error)) *Promise[T] {
+
+	return &Promise[T]{p: promise.NewPromise(func(value interface{}) interface{} {
+		return settle(onSuccess())
+	})}
+}
+
+/*
+	Add new promise with mapping function for current promise
+
+	Go doesn't have generic methods, that's why it isn't a method.
+	JS example: promise.then(result => { ... });
+ */
+func Then[T, U any](p *Promise[T], onSuccess func(value T) (U, error)) *Promise[U] {
+
+	return &Promise[U]{p: p.p.Then(func(value interface{}) interface{} {
+		v, err := cast[T](value)
+		if err != nil {
+			return err
+		}
+		return settle(onSuccess(v))
+	})}
+}
+
+/*
+	Like Then, but handler returns new promise, its result will be used
+ */
+func ThenPromise[T, U any](p *Promise[T], onSuccess func(value T) *Promise[U]) *Promise[U] {
+
+	return From[U](p.p.Then(func(value interface{}) interface{} {
+		v, err := cast[T](value)
+		if err != nil {
+			return err
+		}
+		return onSuccess(v).p
+	}))
+}
+
+/*
+	Add new promise with catch handler for current promise
+
+	Value of current promise is transferred without changes.
+	JS example: promise.catch(error => { ... });
+ */
+func Catch[T any](p *Promise[T], onRejected func(err error) (T, error)) *Promise[T] {
+
+	return From[T](p.p.ThenAndCatch(
+		func(value interface{}) interface{} { return value },
+		func(err error) interface{} { return settle(onRejected(err)) }))
+}
+
+/*
+	resolve data like JS
+ */
+func Resolve[T any](value T) *Promise[T] {
+
+	return NewPromise(func() (T, error) { return value, nil })
+}
+
+/*
+	reject like JS
+ */
+func Reject[T any](err error) *Promise[T] {
+
+	return NewPromise(func() (T, error) {
+		var zero T
+		return zero, err
+	})
+}
+
+/*
+	Wrap untyped promise
+
+	If value of untyped promise isn't T then typed promise will be rejected.
+ */
+func From[T any](p *promise.Promise) *Promise[T] {
+
+	return &Promise[T]{p: p.Then(func(value interface{}) interface{} {
+		v, err := cast[T](value)
+		if err != nil {
+			return err
+		}
+		return v
+	})}
+}
+
+/*
+	Return untyped promise for use with Then, Catch, All and etc.
+ */
+func (p *Promise[T]) Untyped() *promise.Promise {
+
+	return p.p
+}
+
+/*
+	adaptation for backend
+	return current value or timeout error
+ */
+func (p *Promise[T]) Get() (T, error) {
+
+	return result[T](p.p.Get())
+}
+
+/*
+	get result with custom timeout
+ */
+func (p *Promise[T]) GetWithTimeout(timeout time.Duration) (T, error) {
+
+	return result[T](p.p.GetWithTimeout(timeout))
+}
+
+/*
+	Wait result without timeout
+ */
+func (p *Promise[T]) Await() (T, error) {
+
+	return result[T](p.p.Await())
+}
+
+/*
+	Wait result until ctx is done
+ */
+func (p *Promise[T]) AwaitContext(ctx context.Context) (T, error) {
+
+	return result[T](p.p.AwaitContext(ctx))
+}
+
+/*
+	Channel is closed when promise is settled
+ */
+func (p *Promise[T]) Done() <-chan struct{} {
+
+	return p.p.Done()
+}
+
+func (p *Promise[T]) String() string {
+	return p.p.String()
+}
+
+func result[T any](value interface{}, err error) (T, error) {
+
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return cast[T](value)
+}
+
+/*
+	convert typed handler result to untyped result
+ */
+func settle[T any](value T, err error) interface{} {
+
+	if err != nil {
+		return err
+	}
+	return value
+}
+
+func cast[T any](value interface{}) (T, error) {
+
+	var zero T
+	if value == nil {
+		// nil is zero value for pointers, interfaces and etc.
+		return zero, nil
+	}
+	v, ok := value.(T)
+	if !ok {
+		return zero, fmt.Errorf("typed promise: value of type %T is not %v",
+			value, reflect.TypeOf((*T)(nil)).Elem())
+	}
+	return v, nil
+}
@@ -0,0 +1,103 @@
+package typed
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	promise "github.com/danevge/go-promise"
+)
+
+const (
+	testStr1 = "aaa_a-1"
+	testStr2 = "bbb_b-2"
+	testErr1 = "ups 1"
+)
+
+func TestTypedAlonePromise(t *testing.T) {
+
+	value, err := NewPromise(func() (string, error) { return testStr1, nil }).Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestTypedThenChangeType(t *testing.T) {
+
+	p := Then(Resolve("42"), strconv.Atoi)
+	value, err := Then(p, func(d int) (int, error) { return d + 1, nil }).Get()
+
+	assert.Equal(t, 43, value)
+	assert.NoError(t, err)
+}
+
+func TestTypedThenByError(t *testing.T) {
+
+	value, err := Then(Resolve("not int"), strconv.Atoi).Get()
+
+	assert.Equal(t, 0, value)
+	assert.Error(t, err)
+}
+
+func TestTypedErrorTransferToChild(t *testing.T) {
+
+	value, err := Then(Reject[string](fmt.Errorf(testErr1)),
+		func(d string) (string, error) { return d + testStr2, nil }).Get()
+
+	assert.Equal(t, "", value)
+	assert.EqualError(t, err, testErr1)
+}
+
+func TestTypedCatch(t *testing.T) {
+
+	value, err := Catch(Reject[string](fmt.Errorf(testErr1)),
+		func(err error) (string, error) { return testStr2, nil }).Get()
+
+	assert.Equal(t, testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestTypedThenPromise(t *testing.T) {
+
+	value, err := ThenPromise(Resolve(testStr1), func(d string) *Promise[string] {
+		return Resolve(d + testStr2)
+	}).Get()
+
+	assert.Equal(t, testStr1+testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestTypedFromUntyped(t *testing.T) {
+
+	value, err := From[string](promise.Resolve(testStr1)).Get()
+
+	assert.Equal(t, testStr1, value)
+	assert.NoError(t, err)
+}
+
+func TestTypedFromUntypedByWrongType(t *testing.T) {
+
+	value, err := From[int](promise.Resolve(testStr1)).Get()
+
+	assert.Equal(t, 0, value)
+	assert.EqualError(t, err, "typed promise: value of type string is not int")
+}
+
+func TestTypedToUntyped(t *testing.T) {
+
+	value, err := Resolve(testStr1).Untyped().
+		Then(func(d interface{}) interface{} { return d.(string) + testStr2 }).
+		Get()
+
+	assert.Equal(t, testStr1+testStr2, value)
+	assert.NoError(t, err)
+}
+
+func TestTypedNilPointer(t *testing.T) {
+
+	value, err := Resolve[*int](nil).Get()
+
+	assert.Nil(t, value)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,152 @@
+package go_promise
+
+import (
+	"runtime"
+	"sync"
+	"time"
+)
+
+/*
+	Default time between rejection of promise without handlers and its report
+ */
+const DefaultUnhandledRejectionDelay = time.Second
+
+/*
+	onRejection - handler of unhandled rejections, nil disables tracking
+	onHandled - handler of late handling of reported rejection
+	delay - check point after rejection, negative delay disables timer
+ */
+var unhandled = struct {
+	sync.RWMutex
+	onRejection func(p *Promise, err error)
+	onHandled   func(p *Promise)
+	delay       time.Duration
+}{delay: DefaultUnhandledRejectionDelay}
+
+/*
+	Set package-wide handler of rejected promises without handlers, like unhandledRejection of Node.js
+
+	Promise is handled if it has child (Then, Catch, Finally, Timeout and etc.),
+	it's passed to All, Race and etc., it's result of handler or its error is got
+	by Get, Await, AwaitContext or Err.
+
+	Rejection is checked after delay of SetUnhandledRejectionDelay by clock of chain
+	and when promise is collected by garbage collector. Finalizer isn't run for promise
+	which is referenced by its own handler, see runtime.SetFinalizer.
+	Handler is called once per promise in goroutine of timer or finalizer.
+	nil handler disables tracking.
+
+	Use:
+		OnUnhandledRejection(func(p *Promise, err error) {
+			log.Printf("unhandled rejection of promise %v: %v", p.Id(), err)
+		})
+ */
+func OnUnhandledRejection(handler func(p *Promise, err error)) {
+
+	unhandled.Lock()
+	defer unhandled.Unlock()
+	unhandled.onRejection = handler
+}
+
+/*
+	Set package-wide handler of reported rejection which gets handler later, like rejectionHandled of Node.js
+ */
+func OnRejectionHandled(handler func(p *Promise)) {
+
+	unhandled.Lock()
+	defer unhandled.Unlock()
+	unhandled.onHandled = handler
+}
+
+/*
+	Set time between rejection and check of handlers for new rejections
+
+	Zero delay checks rejection immediately in goroutine of rejection, so handler
+	added later is reported by OnRejectionHandled. Negative delay disables timer, unhandled rejection
+	is reported only by garbage collector.
+ */
+func SetUnhandledRejectionDelay(delay time.Duration) {
+
+	unhandled.Lock()
+	defer unhandled.Unlock()
+	unhandled.delay = delay
+}
+
+/*
+	Start check of rejected promise without handlers, it's called by finalize
+ */
+func (p *Promise) trackRejection() {
+
+	unhandled.RLock()
+	enabled, delay := unhandled.onRejection != nil, unhandled.delay
+	unhandled.RUnlock()
+	if !enabled {
+		return
+	}
+
+	switch {
+	case delay == 0:
+		p.checkRejection()
+	case delay > 0:
+		runtime.SetFinalizer(p, (*Promise).checkRejection)
+		p.clock.AfterFunc(delay, p.checkRejection)
+	default:
+		runtime.SetFinalizer(p, (*Promise).checkRejection)
+	}
+}
+
+/*
+	Report rejection if promise isn't handled yet
+ */
+func (p *Promise) checkRejection() {
+
+	p.mutex.Lock()
+	if p.handled || p.reported {
+		p.mutex.Unlock()
+		return
+	}
+	p.reported = true
+	p.mutex.Unlock()
+
+	unhandled.RLock()
+	handler := unhandled.onRejection
+	unhandled.RUnlock()
+	if p.logEnabled(LevelWarn) {
+		p.log(LevelWarn, "unhandled rejection", Field{Key: "error", Value: p.result.err})
+	}
+	if handler != nil {
+		handler(p, p.result.err)
+	}
+}
+
+/*
+	Mark promise as handled, reported rejection is reported as handled
+ */
+func (p *Promise) markHandled() {
+
+	p.mutex.Lock()
+	if p.handled {
+		p.mutex.Unlock()
+		return
+	}
+	p.handled = true
+	reported := p.reported
+	p.mutex.Unlock()
+
+	if reported {
+		p.rejectionHandled()
+	}
+}
+
+func (p *Promise) rejectionHandled() {
+
+	unhandled.RLock()
+	handler := unhandled.onHandled
+	unhandled.RUnlock()
+	if p.logEnabled(LevelInfo) {
+		p.log(LevelInfo, "rejection handled")
+	}
+	if handler != nil {
+		handler(p)
+	}
+}
@@ -0,0 +1,174 @@
+package go_promise
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRejection struct {
+	id  string
+	err error
+}
+
+/*
+	Collect reports of rejections and handlings, promises of other tests are collected too
+ */
+func trackRejections(t *testing.T, delay time.Duration) (rejections chan testRejection, handled chan string) {
+
+	rejections = make(chan testRejection, 100)
+	handled = make(chan string, 100)
+	OnUnhandledRejection(func(p *Promise, err error) { rejections <- testRejection{id: p.Id(), err: err} })
+	OnRejectionHandled(func(p *Promise) { handled <- p.Id() })
+	SetUnhandledRejectionDelay(delay)
+	t.Cleanup(func() {
+		OnUnhandledRejection(nil)
+		OnRejectionHandled(nil)
+		SetUnhandledRejectionDelay(DefaultUnhandledRejectionDelay)
+	})
+	return rejections, handled
+}
+
+func reportedIds(ch chan testRejection) []string {
+
+	var ids []string
+	for {
+		select {
+		case r := <-ch:
+			ids = append(ids, r.id)
+		default:
+			return ids
+		}
+	}
+}
+
+func handledIds(ch chan string) []string {
+
+	var ids []string
+	for {
+		select {
+		case id := <-ch:
+			ids = append(ids, id)
+		default:
+			return ids
+		}
+	}
+}
+
+func TestUnhandledRejection(t *testing.T) {
+
+	rejections, _ := trackRejections(t, time.Second)
+	clock := NewFakeClock(time.Now())
+	deferred := NewDeferredWithContext(WithClock(context.Background(), clock))
+
+	deferred.Reject(fmt.Errorf(testErr1))
+	clock.Advance(time.Second - time.Millisecond)
+	assert.NotContains(t, reportedIds(rejections), deferred.Promise.Id())
+
+	clock.Advance(time.Millisecond)
+	var reported []testRejection
+	for len(rejections) > 0 {
+		if r := <-rejections; r.id == deferred.Promise.Id() {
+			reported = append(reported, r)
+		}
+	}
+	assert.Len(t, reported, 1)
+	assert.EqualError(t, reported[0].err, testErr1)
+}
+
+func TestUnhandledRejectionOfChain(t *testing.T) {
+
+	rejections, _ := trackRejections(t, time.Second)
+	clock := NewFakeClock(time.Now())
+	deferred := NewDeferredWithContext(WithClock(context.Background(), clock))
+	child := deferred.Promise.Then(func(d interface{}) interface{} { return d })
+	caught := deferred.Promise.Catch(func(err error) interface{} { return testStr1 })
+
+	deferred.Reject(fmt.Errorf(testErr1))
+	<-child.Done()
+	<-caught.Done()
+	clock.Advance(time.Second)
+
+	ids := reportedIds(rejections)
+	assert.NotContains(t, ids, deferred.Promise.Id())
+	assert.NotContains(t, ids, caught.Id())
+	// rejection is transferred to child like JS
+	assert.Contains(t, ids, child.Id())
+}
+
+func TestUnhandledRejectionAwait(t *testing.T) {
+
+	rejections, _ := trackRejections(t, time.Second)
+	clock := NewFakeClock(time.Now())
+	promise := NewPromiseWithContext(WithClock(context.Background(), clock),
+		func(ctx context.Context, d interface{}) interface{} { return fmt.Errorf(testErr1) })
+
+	_, err := promise.Await()
+	clock.Advance(time.Second)
+
+	assert.Error(t, err)
+	assert.NotContains(t, reportedIds(rejections), promise.Id())
+}
+
+func TestRejectionHandledLater(t *testing.T) {
+
+	rejections, handled := trackRejections(t, 0)
+	deferred := NewDeferred()
+
+	deferred.Reject(fmt.Errorf(testErr1))
+	assert.Contains(t, reportedIds(rejections), deferred.Promise.Id())
+	assert.NotContains(t, handledIds(handled), deferred.Promise.Id())
+
+	value, _ := deferred.Promise.Catch(func(err error) interface{} { return testStr1 }).Await()
+	deferred.Promise.Await()
+	assert.Equal(t, testStr1, value)
+
+	count := 0
+	for _, id := range handledIds(handled) {
+		if id == deferred.Promise.Id() {
+			count++
+		}
+	}
+	assert.Equal(t, 1, count)
+}
+
+func TestUnhandledRejectionFinalizer(t *testing.T) {
+
+	rejections, _ := trackRejections(t, -1)
+	id := func() string {
+		promise := Reject(fmt.Errorf(testErr1))
+		promise.Await()
+		return promise.Id()
+	}()
+	// Await marks promise as handled, so it isn't reported
+	unhandledId := func() string {
+		return Reject(fmt.Errorf(testErr2)).Id()
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	var ids []string
+	for time.Now().Before(deadline) {
+		runtime.GC()
+		ids = append(ids, reportedIds(rejections)...)
+		if contains(ids, unhandledId) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Contains(t, ids, unhandledId)
+	assert.NotContains(t, ids, id)
+}
+
+func contains(ids []string, id string) bool {
+
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
@@ -3,16 +3,27 @@ package go_promise
 import (
 	"time"
 	"math/rand"
+	"strconv"
+	"sync/atomic"
 )
 
 var letterRunes = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
 
+// count of created ids, it makes id unique in process
+var lastId uint64
+
+/*
+	Id is unique number of promise in base 36 and random letters
+
+	Metrics, Registry and LeakDetector use id as key, so it mustn't repeat.
+ */
 func id(parentUuid string) string {
 
+	own := strconv.FormatUint(atomic.AddUint64(&lastId, 1), 36) + randStringRunes(3)
 	if parentUuid == "" {
-		return randStringRunes(3)
+		return own
 	}
-	return parentUuid + "-" + randStringRunes(3);
+	return parentUuid + "-" + own;
 }
 
 func init() {